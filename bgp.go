@@ -0,0 +1,122 @@
+package goinvestigate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+)
+
+// The regional internet registry which allocated a prefix.
+type Registry int
+
+const (
+	RegistryUnknown Registry = iota
+	RegistryAFRINIC
+	RegistryAPNIC
+	RegistryARIN
+	RegistryLACNIC
+	RegistryRIPE
+)
+
+var registryNames = map[Registry]string{
+	RegistryUnknown: "Unknown",
+	RegistryAFRINIC: "AFRINIC",
+	RegistryAPNIC:   "APNIC",
+	RegistryARIN:    "ARIN",
+	RegistryLACNIC:  "LACNIC",
+	RegistryRIPE:    "RIPE NCC",
+}
+
+func (r Registry) String() string {
+	if name, ok := registryNames[r]; ok {
+		return name
+	}
+	return "Registry(" + strconv.Itoa(int(r)) + ")"
+}
+
+// An autonomous system which announces a route to an IP.
+type ASInfo struct {
+	// The announced prefix the IP is in
+	CIDR         netip.Prefix
+	ASN          int
+	Registry     Registry `json:"ir"`
+	Description  string
+	CreationDate Timestamp  `json:"creation_date"`
+	Extra        *RawFields `json:"-"`
+}
+
+func (a *ASInfo) UnmarshalJSON(b []byte) error {
+	type alias ASInfo
+	return decodeFields(b, (*alias)(a), &a.Extra)
+}
+
+// Where the addresses of a prefix are.
+type PrefixGeo struct {
+	CountryName string `json:"country_name"`
+	// The ISO 3166-1 numeric code of the country
+	CountryCode int        `json:"country_code"`
+	Extra       *RawFields `json:"-"`
+}
+
+func (g *PrefixGeo) UnmarshalJSON(b []byte) error {
+	type alias PrefixGeo
+	return decodeFields(b, (*alias)(g), &g.Extra)
+}
+
+// A prefix announced by an autonomous system.
+type ASPrefix struct {
+	CIDR  netip.Prefix
+	Geo   PrefixGeo
+	Extra *RawFields `json:"-"`
+}
+
+func (p *ASPrefix) UnmarshalJSON(b []byte) error {
+	type alias ASPrefix
+	return decodeFields(b, (*alias)(p), &p.Extra)
+}
+
+// Get the autonomous systems which announce routes to the given IP, one for
+// each prefix the IP is in.
+//
+// For details, see https://sgraph.opendns.com/docs/api#bgp
+func (inv *Investigate) ASForIP(ip string) ([]ASInfo, error) {
+	return inv.ASForIPContext(context.Background(), ip)
+}
+
+// Like ASForIP, but the request is bound to ctx.
+func (inv *Investigate) ASForIPContext(ctx context.Context, ip string) ([]ASInfo, error) {
+	segment, err := ipSegment(ip)
+	if err != nil {
+		return nil, err
+	}
+	var resp []ASInfo
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["as_for_ip"], segment), &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Get the prefixes announced by the autonomous system with the given
+// number.
+//
+// For details, see https://sgraph.opendns.com/docs/api#bgp
+func (inv *Investigate) PrefixesForASN(asn int) ([]ASPrefix, error) {
+	return inv.PrefixesForASNContext(context.Background(), asn)
+}
+
+// Like PrefixesForASN, but the request is bound to ctx.
+func (inv *Investigate) PrefixesForASNContext(ctx context.Context, asn int) ([]ASPrefix, error) {
+	// AS numbers are 32 bits, and 0 is reserved
+	if asn <= 0 || int64(asn) > math.MaxUint32 {
+		return nil, &ValidationError{Kind: "ASN", Input: strconv.Itoa(asn), Reason: "out of range"}
+	}
+	var resp []ASPrefix
+	err := inv.GetParseContext(ctx, fmt.Sprintf(urls["prefixes_for_asn"], strconv.Itoa(asn)), &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package goinvestigate
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestASForIP(t *testing.T) {
+	t.Parallel()
+	bgpInv, _ := newTestClient(t)
+
+	out, err := bgpInv.ASForIP("208.67.222.222")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("got %d systems, should get 1", len(out))
+	}
+
+	as := out[0]
+	if as.ASN != 36692 || as.CIDR != netip.MustParsePrefix("208.67.216.0/21") || as.Registry != RegistryARIN {
+		t.Fatalf("unexpected system %+v", as)
+	}
+
+	if !as.CreationDate.Time.Equal(time.Date(2006, 6, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected creation date %v", as.CreationDate)
+	}
+
+	if !as.CIDR.Contains(netip.MustParseAddr("208.67.222.222")) {
+		t.Fatalf("%v should contain the IP", as.CIDR)
+	}
+
+	if _, err := bgpInv.ASForIP("not an ip"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("%v should be %v", err, ErrInvalidInput)
+	}
+}
+
+func TestPrefixesForASN(t *testing.T) {
+	t.Parallel()
+	bgpInv, _ := newTestClient(t)
+
+	out, err := bgpInv.PrefixesForASN(36692)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 3 || out[0].Geo.CountryCode != 840 || out[1].Geo.CountryName != "United Kingdom" {
+		t.Fatalf("unexpected prefixes %+v", out)
+	}
+
+	if !out[2].CIDR.Addr().Is6() || out[2].CIDR.Bits() != 48 {
+		t.Fatalf("unexpected prefix %v", out[2].CIDR)
+	}
+
+	for _, asn := range []int{0, -1, 1 << 40} {
+		if _, err := bgpInv.PrefixesForASN(asn); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("%d: %v should be %v", asn, err, ErrInvalidInput)
+		}
+	}
+}
+
+func TestRegistryString(t *testing.T) {
+	t.Parallel()
+	if s := RegistryRIPE.String(); s != "RIPE NCC" {
+		t.Fatalf("got %q", s)
+	}
+
+	if s := Registry(9).String(); s != "Registry(9)" {
+		t.Fatalf("got %q", s)
+	}
+}
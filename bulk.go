@@ -0,0 +1,145 @@
+package goinvestigate
+
+import (
+	"context"
+)
+
+// The number of concurrent requests made by the bulk methods of clients
+// which aren't given a concurrency with WithConcurrency.
+const defaultConcurrency = 10
+
+// A Result is the outcome of looking up one item (a domain or IP) in a bulk
+// request.
+type Result[T any] struct {
+	Item  string
+	Value T
+	Err   error
+}
+
+// Looks up every item with lookup, making up to the client's concurrency of
+// requests at once. Results are returned in the same order as items.
+func bulk[T any](ctx context.Context, inv *Investigate, items []string, lookup func(context.Context, string) (T, error)) []Result[T] {
+	return BulkLookup(ctx, inv.concurrency, items, lookup)
+}
+
+// Look up every item with lookup, running up to concurrency lookups at
+// once. Results are returned in the same order as items. The bulk methods
+// of Investigate, such as SecurityBulk, are built on it.
+func BulkLookup[T any](ctx context.Context, concurrency int, items []string, lookup func(context.Context, string) (T, error)) []Result[T] {
+	results := make([]Result[T], 0, len(items))
+	for _, result := range StreamLookup(ctx, concurrency, items, lookup, StreamOrdered()) {
+		results = append(results, result)
+	}
+	return results
+}
+
+// Get the Security Information for each of the given domains concurrently.
+// The results are in the same order as domains, each with its own error.
+//
+// For details, see https://sgraph.opendns.com/docs/api#securityInfo
+func (inv *Investigate) SecurityBulk(domains []string) []Result[*SecurityFeatures] {
+	return inv.SecurityBulkContext(context.Background(), domains)
+}
+
+// Like SecurityBulk, but the requests are bound to ctx.
+func (inv *Investigate) SecurityBulkContext(ctx context.Context, domains []string) []Result[*SecurityFeatures] {
+	return bulk(ctx, inv, domains, inv.SecurityContext)
+}
+
+// Get the related domains of each of the given domains concurrently.
+// The results are in the same order as domains, each with its own error.
+//
+// For details, see https://sgraph.opendns.com/docs/api#relatedDomains
+func (inv *Investigate) RelatedDomainsBulk(domains []string) []Result[[]RelatedDomain] {
+	return inv.RelatedDomainsBulkContext(context.Background(), domains)
+}
+
+// Like RelatedDomainsBulk, but the requests are bound to ctx.
+func (inv *Investigate) RelatedDomainsBulkContext(ctx context.Context, domains []string) []Result[[]RelatedDomain] {
+	return bulk(ctx, inv, domains, inv.RelatedDomainsContext)
+}
+
+// Get the cooccurrences of each of the given domains concurrently.
+// The results are in the same order as domains, each with its own error.
+//
+// For details, see https://sgraph.opendns.com/docs/api#co-occurrences
+func (inv *Investigate) CooccurrencesBulk(domains []string) []Result[[]Cooccurrence] {
+	return inv.CooccurrencesBulkContext(context.Background(), domains)
+}
+
+// Like CooccurrencesBulk, but the requests are bound to ctx.
+func (inv *Investigate) CooccurrencesBulkContext(ctx context.Context, domains []string) []Result[[]Cooccurrence] {
+	return bulk(ctx, inv, domains, inv.CooccurrencesContext)
+}
+
+// Get the domain tagging dates of each of the given domains concurrently.
+// The results are in the same order as domains, each with its own error.
+//
+// For details, see https://sgraph.opendns.com/docs/api#latest_tags
+func (inv *Investigate) DomainTagsBulk(domains []string) []Result[[]DomainTag] {
+	return inv.DomainTagsBulkContext(context.Background(), domains)
+}
+
+// Like DomainTagsBulk, but the requests are bound to ctx.
+func (inv *Investigate) DomainTagsBulkContext(ctx context.Context, domains []string) []Result[[]DomainTag] {
+	return bulk(ctx, inv, domains, inv.DomainTagsContext)
+}
+
+// Get the RR (Resource Record) History of each of the given domains
+// concurrently. The results are in the same order as domains, each with its
+// own error.
+//
+// For details, see https://sgraph.opendns.com/docs/api#dnsrr_domain
+func (inv *Investigate) DomainRRHistoryBulk(domains []string, queryType QueryType) []Result[*DomainRRHistory] {
+	return inv.DomainRRHistoryBulkContext(context.Background(), domains, queryType)
+}
+
+// Like DomainRRHistoryBulk, but the requests are bound to ctx.
+func (inv *Investigate) DomainRRHistoryBulkContext(ctx context.Context, domains []string, queryType QueryType) []Result[*DomainRRHistory] {
+	return bulk(ctx, inv, domains, func(ctx context.Context, domain string) (*DomainRRHistory, error) {
+		return inv.DomainRRHistoryContext(ctx, domain, queryType)
+	})
+}
+
+// Get the RR (Resource Record) History of each of the given IPs
+// concurrently. The results are in the same order as ips, each with its own
+// error.
+//
+// For details, see https://sgraph.opendns.com/docs/api#dnsrr_ip
+func (inv *Investigate) IpRRHistoryBulk(ips []string, queryType QueryType) []Result[*IPRRHistory] {
+	return inv.IpRRHistoryBulkContext(context.Background(), ips, queryType)
+}
+
+// Like IpRRHistoryBulk, but the requests are bound to ctx.
+func (inv *Investigate) IpRRHistoryBulkContext(ctx context.Context, ips []string, queryType QueryType) []Result[*IPRRHistory] {
+	return bulk(ctx, inv, ips, func(ctx context.Context, ip string) (*IPRRHistory, error) {
+		return inv.IpRRHistoryContext(ctx, ip, queryType)
+	})
+}
+
+// Get the latest known malicious domains of each of the given IPs
+// concurrently. The results are in the same order as ips, each with its own
+// error.
+//
+// For details, see https://sgraph.opendns.com/docs/api#latest_domains
+func (inv *Investigate) LatestDomainsBulk(ips []string) []Result[[]string] {
+	return inv.LatestDomainsBulkContext(context.Background(), ips)
+}
+
+// Like LatestDomainsBulk, but the requests are bound to ctx.
+func (inv *Investigate) LatestDomainsBulkContext(ctx context.Context, ips []string) []Result[[]string] {
+	return bulk(ctx, inv, ips, inv.LatestDomainsContext)
+}
+
+// Get the risk score of each of the given domains concurrently. The results
+// are in the same order as domains, each with its own error.
+//
+// For details, see https://sgraph.opendns.com/docs/api#risk-score
+func (inv *Investigate) RiskScoreBulk(domains []string) []Result[*DomainRiskScore] {
+	return inv.RiskScoreBulkContext(context.Background(), domains)
+}
+
+// Like RiskScoreBulk, but the requests are bound to ctx.
+func (inv *Investigate) RiskScoreBulkContext(ctx context.Context, domains []string) []Result[*DomainRiskScore] {
+	return bulk(ctx, inv, domains, inv.RiskScoreContext)
+}
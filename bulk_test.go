@@ -0,0 +1,101 @@
+package goinvestigate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSecurityBulk(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		if strings.Contains(r.URL.Path, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"attack": "` + r.URL.Path + `"}`))
+	}))
+	defer ts.Close()
+
+	bulkInv := New("test_key",
+		WithBaseURL(ts.URL),
+		WithConcurrency(3),
+		WithRetryPolicy(&ExponentialBackoff{MaxAttempts: 1}),
+	)
+
+	domains := []string{"a.com", "b.com", "missing.com", "c.com", "d.com", "e.com", "f.com"}
+	results := bulkInv.SecurityBulk(domains)
+
+	if len(results) != len(domains) {
+		t.Fatalf("got %d results for %d domains", len(results), len(domains))
+	}
+
+	for i, result := range results {
+		if result.Item != domains[i] {
+			t.Fatalf("result %d is for %s, should be for %s", i, result.Item, domains[i])
+		}
+
+		if domains[i] == "missing.com" {
+			if !errors.Is(result.Err, ErrNotFound) {
+				t.Fatalf("%v should be %v", result.Err, ErrNotFound)
+			}
+			continue
+		}
+
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+
+		if result.Value.Attack != "/security/name/"+domains[i]+".json" {
+			t.Fatalf("result for %s has the wrong value %+v", domains[i], result.Value)
+		}
+	}
+
+	if maxInFlight > 3 {
+		t.Fatalf("made %d concurrent requests, should make at most 3", maxInFlight)
+	}
+}
+
+func TestDomainRRHistoryBulk(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"rrs_tf": [{"first_seen": "2013-07-31", "rrs": []}]}`))
+	}))
+	defer ts.Close()
+
+	bulkInv := New("test_key", WithBaseURL(ts.URL))
+	results := bulkInv.DomainRRHistoryBulk([]string{"a.com", "b.com"}, "A")
+
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+		if len(result.Value.RRPeriods) != 1 {
+			t.Fatalf("unexpected value %+v", result.Value)
+		}
+	}
+
+	results = bulkInv.DomainRRHistoryBulk([]string{"a.com"}, "BOGUS")
+	if !errors.Is(results[0].Err, ErrUnsupportedQueryType) {
+		t.Fatalf("%v should be %v", results[0].Err, ErrUnsupportedQueryType)
+	}
+}
@@ -0,0 +1,298 @@
+package goinvestigate
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The TTL of responses from endpoints which have no TTL of their own.
+const DefaultCacheTTL = time.Hour
+
+// How long responses from each endpoint are cached by default, keyed by the
+// endpoint names in the client's URL table. Data which changes slowly, like
+// RR history, is kept longer. Override these with WithCacheTTL.
+var defaultCacheTTLs = map[string]time.Duration{
+	"ip":                24 * time.Hour,
+	"domain":            24 * time.Hour,
+	"categorization":    time.Hour,
+	"related":           6 * time.Hour,
+	"cooccurrences":     6 * time.Hour,
+	"security":          time.Hour,
+	"tags":              6 * time.Hour,
+	"latest_domains":    time.Hour,
+	"categories":        24 * time.Hour,
+	"whois":             24 * time.Hour,
+	"whois_history":     24 * time.Hour,
+	"whois_emails":      24 * time.Hour,
+	"whois_nameservers": 24 * time.Hour,
+	"pdns_domain":       24 * time.Hour,
+	"pdns_name":         24 * time.Hour,
+	"pdns_ip":           24 * time.Hour,
+	"pdns_raw":          24 * time.Hour,
+	"pdns_timeline":     time.Hour,
+	"risk_score":        time.Hour,
+	"subdomains":        6 * time.Hour,
+	"as_for_ip":         24 * time.Hour,
+	"prefixes_for_asn":  24 * time.Hour,
+	"volume":            time.Hour,
+}
+
+// A Cache stores API response bodies, keyed by API key and request.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key, if it hasn't expired.
+	Get(key string) ([]byte, bool)
+	// Set stores value under key for ttl.
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// CacheStats holds the number of cache hits and misses of a client.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+type cacheCounters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// CacheStats returns the number of cache hits and misses so far.
+func (inv *Investigate) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   inv.cacheStat.hits.Load(),
+		Misses: inv.cacheStat.misses.Load(),
+	}
+}
+
+// The TTL of responses from the endpoint the given URI belongs to.
+func (inv *Investigate) cacheTTL(subUri string) time.Duration {
+	path := subUri
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	name := endpointName(path)
+	if ttl, ok := inv.cacheTTLs[name]; ok {
+		return ttl
+	}
+	if ttl, ok := defaultCacheTTLs[name]; ok {
+		return ttl
+	}
+	return DefaultCacheTTL
+}
+
+// Performs the request and returns the response body, unless the body is
+// already in the client's cache.
+func (inv *Investigate) cachedRequest(ctx context.Context, method, subUri string, body io.Reader) (io.ReadCloser, error) {
+	ttl := inv.cacheTTL(subUri)
+	if inv.cache == nil || ttl <= 0 {
+		return inv.uncachedRequest(ctx, method, subUri, body)
+	}
+
+	// a cache shared between clients mustn't serve one key's or server's
+	// responses to another, so both are part of the cache key, the API key
+	// hashed to keep it out of logs and disk caches
+	keySum := sha256.Sum256([]byte(inv.key))
+	key := hex.EncodeToString(keySum[:8]) + " " + method + " " + inv.baseUrl + subUri
+	if body != nil {
+		reqBody, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		key += " " + string(reqBody)
+		body = bytes.NewReader(reqBody)
+	}
+
+	if cached, ok := inv.cache.Get(key); ok {
+		inv.cacheStat.hits.Add(1)
+		inv.Logf("cache hit: %s", key)
+		return ioutil.NopCloser(bytes.NewReader(cached)), nil
+	}
+	inv.cacheStat.misses.Add(1)
+
+	respBody, err := inv.uncachedRequest(ctx, method, subUri, body)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	value, err := ioutil.ReadAll(respBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// don't hold on to a broken response for a whole TTL
+	if json.Valid(value) {
+		inv.cache.Set(key, value, ttl)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(value)), nil
+}
+
+func (inv *Investigate) uncachedRequest(ctx context.Context, method, subUri string, body io.Reader) (io.ReadCloser, error) {
+	if method == "POST" {
+		resp, err := inv.PostContext(ctx, subUri, body)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Body, nil
+	}
+
+	resp, err := inv.GetContext(ctx, subUri)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// LRUCache is an in-memory Cache which holds up to a fixed number of
+// entries, evicting the least recently used one to make room for new ones.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// Build an LRUCache which holds up to maxEntries responses. If maxEntries is
+// 0 or less, the cache is unbounded and never evicts entries to make room.
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key, value, expires})
+
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired ones
+// which haven't been evicted yet.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// DiskCache is a Cache which keeps each entry in a file of its own under a
+// directory, so that cached responses survive restarts. Expired entries are
+// removed when they are next looked up.
+type DiskCache struct {
+	dir string
+}
+
+// Build a DiskCache which stores its entries under dir, creating it if
+// needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Entries are stored as their expiry time, in Unix nanoseconds, followed by
+// the value.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	data, err := ioutil.ReadFile(path)
+	if err != nil || len(data) < 8 {
+		return nil, false
+	}
+
+	expires := time.Unix(0, int64(binary.BigEndian.Uint64(data[:8])))
+	if time.Now().After(expires) {
+		os.Remove(path)
+		return nil, false
+	}
+
+	return data[8:], true
+}
+
+func (c *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	data := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(time.Now().Add(ttl).UnixNano()))
+	data = append(data, value...)
+
+	// write to a temporary file first, so that concurrent readers never see
+	// a partially written entry
+	tmp, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,192 @@
+package goinvestigate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	t.Parallel()
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), time.Hour)
+	cache.Set("b", []byte("2"), time.Hour)
+
+	// a is now the most recently used, so b is evicted next
+	if v, ok := cache.Get("a"); !ok || string(v) != "1" {
+		t.Fatalf("a: got %q, %v", v, ok)
+	}
+	cache.Set("c", []byte("3"), time.Hour)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("b should have been evicted")
+	}
+
+	if cache.Len() != 2 {
+		t.Fatalf("cache holds %d entries, should hold 2", cache.Len())
+	}
+
+	cache.Set("d", []byte("4"), -time.Second)
+	if _, ok := cache.Get("d"); ok {
+		t.Fatal("d should have expired")
+	}
+}
+
+func TestLRUCacheUnbounded(t *testing.T) {
+	t.Parallel()
+	cache := NewLRUCache(0)
+	for i := 0; i < 100; i++ {
+		cache.Set(strconv.Itoa(i), []byte("v"), time.Hour)
+	}
+
+	if cache.Len() != 100 {
+		t.Fatalf("cache holds %d entries, should hold 100", cache.Len())
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	t.Parallel()
+	cache, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Set("GET /security/name/test.com.json", []byte(`{"dga_score": 1}`), time.Hour)
+	if v, ok := cache.Get("GET /security/name/test.com.json"); !ok || string(v) != `{"dga_score": 1}` {
+		t.Fatalf("got %q, %v", v, ok)
+	}
+
+	if _, ok := cache.Get("GET /security/name/other.com.json"); ok {
+		t.Fatal("should not find an entry which was never set")
+	}
+
+	cache.Set("expired", []byte("{}"), -time.Second)
+	if _, ok := cache.Get("expired"); ok {
+		t.Fatal("entry should have expired")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method == "POST" {
+			w.Write([]byte(`{"www.test.com": {"status": 1}}`))
+			return
+		}
+		w.Write([]byte(`{"dga_score": -3.5}`))
+	}))
+	defer ts.Close()
+
+	cacheInv := New("test_key",
+		WithBaseURL(ts.URL),
+		WithCache(NewLRUCache(10)),
+		WithCacheTTL("latest_domains", 0),
+	)
+
+	for i := 0; i < 3; i++ {
+		out, err := cacheInv.Security("www.test.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.DGAScore != -3.5 {
+			t.Fatalf("unexpected response %+v", out)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cacheInv.Categorizations([]string{"www.test.com"}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 2 {
+		t.Fatalf("made %d requests, should have made 2", calls)
+	}
+
+	if stats := cacheInv.CacheStats(); stats.Hits != 3 || stats.Misses != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// caching is turned off for latest_domains
+	cacheInv.LatestDomains("8.8.8.8")
+	cacheInv.LatestDomains("8.8.8.8")
+	if calls != 4 {
+		t.Fatalf("made %d requests, should have made 4", calls)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	t.Parallel()
+	ttlInv := New("test_key", WithCacheTTL("security", time.Minute))
+
+	ttls := map[string]time.Duration{
+		"/domains/categorization/test.com?showLabels=true": time.Hour,
+		"/dnsdb/name/A/test.com.json":                      24 * time.Hour,
+		"/security/name/test.com.json":                     time.Minute,
+		"/some/new/endpoint":                               DefaultCacheTTL,
+	}
+
+	for uri, ref := range ttls {
+		if ttl := ttlInv.cacheTTL(uri); ttl != ref {
+			t.Fatalf("%s: TTL %v should be %v", uri, ttl, ref)
+		}
+	}
+}
+
+func TestCacheSharedBetweenKeys(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer good_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"dga_score": -3.5}`))
+	}))
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	goodInv := New("good_key", WithBaseURL(ts.URL), WithCache(cache))
+	badInv := New("bad_key", WithBaseURL(ts.URL), WithCache(cache))
+
+	if _, err := goodInv.Security("www.test.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := badInv.Security("www.test.com"); err == nil {
+		t.Fatal("a bad key should not be served another key's cached response")
+	}
+
+	if calls != 2 {
+		t.Fatalf("made %d requests, should have made 2", calls)
+	}
+}
+
+func TestCacheSharedBetweenServers(t *testing.T) {
+	t.Parallel()
+	newServer := func(score string) *httptest.Server {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"dga_score": ` + score + `}`))
+		}))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	staging, prod := newServer("-1"), newServer("-2")
+
+	cache := NewLRUCache(10)
+	stagingInv := New("test_key", WithBaseURL(staging.URL), WithCache(cache))
+	prodInv := New("test_key", WithBaseURL(prod.URL), WithCache(cache))
+
+	if out, err := stagingInv.Security("www.test.com"); err != nil || out.DGAScore != -1 {
+		t.Fatalf("got %+v, %v", out, err)
+	}
+
+	if out, err := prodInv.Security("www.test.com"); err != nil || out.DGAScore != -2 {
+		t.Fatalf("got %+v, %v; should not be served another server's cached response", out, err)
+	}
+}
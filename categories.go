@@ -0,0 +1,189 @@
+package goinvestigate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// The status of a domain, as given in a DomainCategorization.
+type DomainStatus int
+
+const (
+	StatusMalicious DomainStatus = -1
+	StatusUnknown   DomainStatus = 0
+	StatusBenign    DomainStatus = 1
+)
+
+func (s DomainStatus) IsMalicious() bool {
+	return s == StatusMalicious
+}
+
+func (s DomainStatus) IsBenign() bool {
+	return s == StatusBenign
+}
+
+// Whether the domain hasn't been classified. Statuses the API doesn't
+// document count as unknown too.
+func (s DomainStatus) IsUnknown() bool {
+	return !s.IsMalicious() && !s.IsBenign()
+}
+
+func (s DomainStatus) String() string {
+	switch s {
+	case StatusMalicious:
+		return "malicious"
+	case StatusBenign:
+		return "benign"
+	case StatusUnknown:
+		return "unknown"
+	}
+	return fmt.Sprintf("DomainStatus(%d)", int(s))
+}
+
+// A CategoryRegistry translates category IDs, as given in categorizations
+// fetched without labels, into their labels. It is safe for concurrent use.
+//
+// Every client has one, which starts out with a snapshot of the categories
+// bundled with this package, and can be brought up to date with
+// RefreshCategories:
+//
+//	cat, err := inv.Categorization("www.amazon.com", false)
+//	...
+//	labelled := inv.CategoryRegistry().Translate(*cat)
+type CategoryRegistry struct {
+	mu     sync.RWMutex
+	labels map[string]string
+	ids    map[string]string
+}
+
+// Build a CategoryRegistry from a map of category IDs to labels, as returned
+// by Categories. If labels is nil, the bundled snapshot is used.
+func NewCategoryRegistry(labels map[string]string) *CategoryRegistry {
+	if labels == nil {
+		labels = categorySnapshot
+	}
+	r := new(CategoryRegistry)
+	r.Update(labels)
+	return r
+}
+
+// A copy of the category IDs and labels bundled with this package.
+func BundledCategories() map[string]string {
+	labels := make(map[string]string, len(categorySnapshot))
+	for id, label := range categorySnapshot {
+		labels[id] = label
+	}
+	return labels
+}
+
+// Replace the registry's categories with the given ones.
+func (r *CategoryRegistry) Update(labels map[string]string) {
+	newLabels := make(map[string]string, len(labels))
+	ids := make(map[string]string, len(labels))
+	for id, label := range labels {
+		newLabels[id] = label
+		// several IDs can share a label; use the lowest consistently
+		if other, ok := ids[label]; !ok || lessID(id, other) {
+			ids[label] = id
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.labels = newLabels
+	r.ids = ids
+}
+
+// orders numeric IDs by value, rather than as strings
+func lessID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// The number of categories in the registry.
+func (r *CategoryRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.labels)
+}
+
+// The label of the category with the given ID.
+func (r *CategoryRegistry) Label(id string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	label, ok := r.labels[id]
+	return label, ok
+}
+
+// The ID of the category with the given label.
+func (r *CategoryRegistry) ID(label string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.ids[label]
+	return id, ok
+}
+
+// The labels of the given category IDs. IDs the registry doesn't know are
+// kept as they are.
+func (r *CategoryRegistry) Labels(ids []string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	labels := make([]string, len(ids))
+	for i, id := range ids {
+		if label, ok := r.labels[id]; ok {
+			labels[i] = label
+		} else {
+			labels[i] = id
+		}
+	}
+	return labels
+}
+
+// A copy of the categorization with its category IDs replaced by labels.
+func (r *CategoryRegistry) Translate(dc DomainCategorization) DomainCategorization {
+	dc.ContentCategories = r.Labels(dc.ContentCategories)
+	dc.SecurityCategories = r.Labels(dc.SecurityCategories)
+	return dc
+}
+
+// Get the labels of every category, by ID.
+//
+// For details, see https://sgraph.opendns.com/docs/api#categories
+func (inv *Investigate) Categories() (map[string]string, error) {
+	return inv.CategoriesContext(context.Background())
+}
+
+// Like Categories, but the request is bound to ctx.
+func (inv *Investigate) CategoriesContext(ctx context.Context) (map[string]string, error) {
+	resp := make(map[string]string)
+	err := inv.GetParseContext(ctx, urls["categories"], &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// The client's CategoryRegistry, which holds the bundled snapshot of the
+// categories until RefreshCategories is called.
+func (inv *Investigate) CategoryRegistry() *CategoryRegistry {
+	return inv.categories
+}
+
+// Fetch the categories from the API into the client's CategoryRegistry. If
+// the request fails, the registry is left as it was.
+func (inv *Investigate) RefreshCategories() error {
+	return inv.RefreshCategoriesContext(context.Background())
+}
+
+// Like RefreshCategories, but the request is bound to ctx.
+func (inv *Investigate) RefreshCategoriesContext(ctx context.Context) error {
+	labels, err := inv.CategoriesContext(ctx)
+	if err != nil {
+		return err
+	}
+	inv.categories.Update(labels)
+	return nil
+}
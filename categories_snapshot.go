@@ -0,0 +1,76 @@
+package goinvestigate
+
+// a snapshot of the labels of each category ID, as given by the categories
+// endpoint, so that IDs can be translated without a request
+var categorySnapshot = map[string]string{
+	"0":   "Adware",
+	"1":   "Alcohol",
+	"2":   "Auctions",
+	"3":   "Blogs",
+	"4":   "Chat",
+	"5":   "Classifieds",
+	"6":   "Dating",
+	"7":   "Drugs",
+	"8":   "Ecommerce/Shopping",
+	"9":   "File Storage",
+	"10":  "Gambling",
+	"11":  "Games",
+	"12":  "Hate/Discrimination",
+	"13":  "Health and Fitness",
+	"14":  "Humor",
+	"15":  "Instant Messaging",
+	"16":  "Jobs/Employment",
+	"17":  "Movies",
+	"18":  "News/Media",
+	"19":  "P2P/File sharing",
+	"20":  "Photo Sharing",
+	"21":  "Portals",
+	"22":  "Radio",
+	"23":  "Search Engines",
+	"24":  "Social Networking",
+	"25":  "Software/Technology",
+	"26":  "Television",
+	"28":  "Video Sharing",
+	"29":  "Visual Search Engines",
+	"30":  "Weapons",
+	"31":  "Webmail",
+	"32":  "Business Services",
+	"33":  "Educational Institutions",
+	"34":  "Financial Institutions",
+	"35":  "Government",
+	"36":  "Music",
+	"37":  "Parked Domains",
+	"38":  "Tobacco",
+	"39":  "Sports",
+	"40":  "Adult Themes",
+	"41":  "Lingerie/Bikini",
+	"42":  "Nudity",
+	"43":  "Proxy/Anonymizer",
+	"44":  "Pornography",
+	"45":  "Sexuality",
+	"46":  "Tasteless",
+	"47":  "Academic Fraud",
+	"48":  "Automotive",
+	"49":  "Forums/Message boards",
+	"50":  "Non-Profits",
+	"51":  "Podcasts",
+	"52":  "Politics",
+	"53":  "Religious",
+	"54":  "Research/Reference",
+	"55":  "Travel",
+	"57":  "Anime/Manga/Webcomic",
+	"58":  "Web Spam",
+	"59":  "Typo Squatting",
+	"60":  "Drive-by Downloads/Exploits",
+	"61":  "Dynamic DNS",
+	"62":  "Mobile Threats",
+	"63":  "High Risk Sites and Locations",
+	"64":  "Command and Control",
+	"65":  "Command and Control",
+	"66":  "Malware",
+	"67":  "Malware",
+	"68":  "Phishing",
+	"108": "Newly Seen Domains",
+	"109": "Potentially Harmful",
+	"110": "DNS Tunneling VPN",
+}
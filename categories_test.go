@@ -0,0 +1,75 @@
+package goinvestigate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDomainStatus(t *testing.T) {
+	t.Parallel()
+	var cats map[string]DomainCategorization
+	err := json.Unmarshal([]byte(`{"a.ru": {"status": -1}, "b.ru": {"status": 1}, "c.ru": {"status": 0}}`), &cats)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cats["a.ru"].Status.IsMalicious() || !cats["b.ru"].Status.IsBenign() || !cats["c.ru"].Status.IsUnknown() {
+		t.Fatalf("unexpected statuses %v", cats)
+	}
+
+	if s := DomainStatus(2); !s.IsUnknown() || s.String() != "DomainStatus(2)" {
+		t.Fatalf("%v should be unknown", s)
+	}
+}
+
+func TestCategoryRegistry(t *testing.T) {
+	t.Parallel()
+	reg := NewCategoryRegistry(nil)
+	if label, ok := reg.Label("8"); !ok || label != "Ecommerce/Shopping" {
+		t.Fatalf("got %q, %v", label, ok)
+	}
+
+	// Malware has two IDs
+	if id, ok := reg.ID("Malware"); !ok || id != "66" {
+		t.Fatalf("got %q, %v", id, ok)
+	}
+
+	dc := DomainCategorization{
+		Status:             StatusMalicious,
+		ContentCategories:  []string{"8", "9999"},
+		SecurityCategories: []string{"67"},
+	}
+	ref := DomainCategorization{
+		Status:             StatusMalicious,
+		ContentCategories:  []string{"Ecommerce/Shopping", "9999"},
+		SecurityCategories: []string{"Malware"},
+	}
+	if out := reg.Translate(dc); !reflect.DeepEqual(out, ref) {
+		t.Fatalf("%v should be %v", out, ref)
+	}
+
+	// the original is left alone
+	if dc.ContentCategories[0] != "8" {
+		t.Fatalf("Translate modified its argument: %v", dc)
+	}
+}
+
+func TestRefreshCategories(t *testing.T) {
+	t.Parallel()
+	catInv, srv := newTestClient(t)
+	srv.SetResponse("/domains/categories", `{"8": "Shopping", "200": "Brand New"}`)
+
+	if catInv.CategoryRegistry().Len() != len(BundledCategories()) {
+		t.Fatal("registry should start with the bundled snapshot")
+	}
+
+	if err := catInv.RefreshCategories(); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := catInv.CategoryRegistry()
+	if label, _ := reg.Label("200"); label != "Brand New" || reg.Len() != 2 {
+		t.Fatalf("registry wasn't refreshed: %v", reg.Labels([]string{"8", "200"}))
+	}
+}
@@ -0,0 +1,124 @@
+package goinvestigate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// the most of an error response body which is kept in an APIError
+const maxErrorBodySize = 64 * 1024
+
+// Errors which an API failure can be matched against with errors.Is, e.g.:
+//
+//	_, err := inv.Security("www.test.com")
+//	if errors.Is(err, goinvestigate.ErrRateLimited) {
+//		// back off and try again later
+//	}
+var (
+	// The API key was rejected (HTTP 401 or 403).
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// The requested resource does not exist (HTTP 404).
+	ErrNotFound = errors.New("not found")
+
+	// Too many requests were made with the API key (HTTP 429).
+	ErrRateLimited = errors.New("rate limited")
+
+	// The response body could not be decoded.
+	ErrMalformedResponse = errors.New("received a malformed response body")
+
+	// The query type is not supported by the RR history endpoints.
+	ErrUnsupportedQueryType = errors.New("unsupported query type")
+)
+
+// An APIError is returned when the Investigate API responds with an
+// HTTP error status. Use errors.As to get at its details:
+//
+//	var apiErr *goinvestigate.APIError
+//	if errors.As(err, &apiErr) {
+//		log.Printf("%d from %s: %s", apiErr.StatusCode, apiErr.Endpoint, apiErr.Body)
+//	}
+type APIError struct {
+	StatusCode int
+	Method     string
+	// The path of the request, e.g. /security/name/www.test.com.json
+	Endpoint string
+	// The response body, truncated to 64KB
+	Body []byte
+	// The value of the response's X-Request-Id header, if it had one
+	RequestID string
+}
+
+// Builds an APIError out of the given response, consuming its body.
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Endpoint:   req.URL.Path,
+		Body:       body,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	if body := strings.TrimSpace(string(e.Body)); body != "" {
+		msg += ": " + body
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error matching the status code, if any, so
+// that errors.Is(err, ErrNotFound) and friends work on an APIError.
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// A ChunkFailure is a chunk of domains which could not be categorized.
+type ChunkFailure struct {
+	Domains []string
+	Err     error
+}
+
+// A ChunkError is returned by Categorizations when some of the chunks the
+// domains were split into could not be categorized, or some of the domains
+// were invalid. Failures lists the invalid domains first, then the failed
+// chunks in the order they were sent. errors.Is and errors.As look through
+// the error of each chunk.
+type ChunkError struct {
+	Failures []ChunkFailure
+}
+
+func (e *ChunkError) Error() string {
+	if len(e.Failures) == 0 {
+		return "0 chunks failed"
+	}
+
+	failed := 0
+	for _, f := range e.Failures {
+		failed += len(f.Domains)
+	}
+	return fmt.Sprintf("%d chunks (%d domains) failed, first error: %v",
+		len(e.Failures), failed, e.Failures[0].Err)
+}
+
+func (e *ChunkError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
@@ -0,0 +1,123 @@
+package goinvestigate
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIErrors(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		status   int
+		sentinel error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+	}
+
+	for _, c := range cases {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", "abc123")
+			w.WriteHeader(c.status)
+			w.Write([]byte(`{"errorMessage": "nope"}`))
+		}))
+
+		errInv := New("test_key", WithBaseURL(ts.URL), WithRetryPolicy(&ExponentialBackoff{MaxAttempts: 1}))
+		_, err := errInv.Security("www.test.com")
+		ts.Close()
+
+		if !errors.Is(err, c.sentinel) {
+			t.Fatalf("%d: %v should be %v", c.status, err, c.sentinel)
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%d: %v should be an *APIError", c.status, err)
+		}
+
+		if apiErr.StatusCode != c.status ||
+			apiErr.Endpoint != "/security/name/www.test.com.json" ||
+			string(apiErr.Body) != `{"errorMessage": "nope"}` ||
+			apiErr.RequestID != "abc123" {
+			t.Fatalf("%d: unexpected error details: %+v", c.status, apiErr)
+		}
+	}
+}
+
+func TestServerErrorRetried(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	errInv := New("test_key",
+		WithBaseURL(ts.URL),
+		WithLogger(log.New(ioutil.Discard, "", 0)),
+		WithRetryPolicy(&ExponentialBackoff{MaxAttempts: 3}),
+	)
+	_, err := errInv.Security("www.test.com")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("%v should be a 502 *APIError", err)
+	}
+
+	if calls != 3 {
+		t.Fatalf("made %d requests, should have made 3", calls)
+	}
+}
+
+func TestMalformedResponse(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"www.test.com": `))
+	}))
+	defer ts.Close()
+
+	errInv := New("test_key", WithBaseURL(ts.URL))
+	_, err := errInv.Security("www.test.com")
+
+	if !errors.Is(err, ErrMalformedResponse) {
+		t.Fatalf("%v should be %v", err, ErrMalformedResponse)
+	}
+
+	_, err = errInv.DomainRRHistory("www.test.com", "BOGUS")
+
+	if !errors.Is(err, ErrUnsupportedQueryType) {
+		t.Fatalf("%v should be %v", err, ErrUnsupportedQueryType)
+	}
+}
+
+func TestParseNonPointer(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"dga_score": -3.5}`))
+	}))
+	defer ts.Close()
+
+	errInv := New("test_key", WithBaseURL(ts.URL))
+	var out SecurityFeatures
+	err := errInv.GetParse("/security/name/www.test.com.json", out)
+
+	var invalidErr *json.InvalidUnmarshalError
+	if !errors.As(err, &invalidErr) || errors.Is(err, ErrMalformedResponse) {
+		t.Fatalf("%v should be a *json.InvalidUnmarshalError, not %v", err, ErrMalformedResponse)
+	}
+}
+
+func TestChunkErrorEmpty(t *testing.T) {
+	t.Parallel()
+	if msg := (&ChunkError{}).Error(); msg != "0 chunks failed" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
@@ -4,16 +4,25 @@ API for the OpenDNS Security Graph / Investigate.
 To use it, use your Investigate API key to build an Investigate object.
 
 	key := "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
-	inv, err := goinvestigate.New(key)
-
-	if err != nil {
-		log.Fatal(err)
-	}
+	inv := goinvestigate.New(key)
 
 Then you can call any API method, e.g.:
-	data, err := inv.DomainRRHistory("www.test.com")
+
+	data, err := inv.DomainRRHistory("www.test.com", goinvestigate.QueryA)
+
 which returns a DomainRRHistory object.
 
+Every API method also has a variant which takes a context.Context as its
+first argument, e.g.:
+
+	data, err := inv.DomainRRHistoryContext(ctx, "www.test.com", goinvestigate.QueryA)
+
+Cancelling the context aborts the request, along with any pending retries.
+
+Domains, IPs and email addresses are normalized before they're used in a
+request (see NormalizeDomain, NormalizeIP and NormalizeEmail), and invalid
+ones are rejected with a *ValidationError without making a request.
+
 Be sure to set runtime.GOMAXPROCS() in the init() function of your program to enable
 concurrency.
 
@@ -24,6 +33,7 @@ package goinvestigate
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -33,107 +43,218 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	baseUrl    = "https://investigate.api.opendns.com"
 	maxTries   = 5
 	timeLayout = "2006/01/02/15"
+
+	// the most domains the categorization endpoint accepts in one request
+	maxCategorizationBatch = 1000
 )
 
 // format strings for API URIs
 var urls map[string]string = map[string]string{
-	"ip":             "/dnsdb/ip/%s/%s.json",
-	"domain":         "/dnsdb/name/%s/%s.json",
-	"categorization": "/domains/categorization/%s",
-	"related":        "/links/name/%s.json",
-	"cooccurrences":  "/recommendations/name/%s.json",
-	"security":       "/security/name/%s.json",
-	"tags":           "/domains/%s/latest_tags",
-	"latest_domains": "/ips/%s/latest_domains",
-}
-
-var supportedQueryTypes map[string]int = map[string]int{
-	"A":     1,
-	"NS":    1,
-	"MX":    1,
-	"TXT":   1,
-	"CNAME": 1,
+	"ip":                "/dnsdb/ip/%s/%s.json",
+	"domain":            "/dnsdb/name/%s/%s.json",
+	"categorization":    "/domains/categorization/%s",
+	"related":           "/links/name/%s.json",
+	"cooccurrences":     "/recommendations/name/%s.json",
+	"security":          "/security/name/%s.json",
+	"tags":              "/domains/%s/latest_tags",
+	"latest_domains":    "/ips/%s/latest_domains",
+	"categories":        "/domains/categories",
+	"whois":             "/whois/%s",
+	"whois_history":     "/whois/%s/history",
+	"whois_emails":      "/whois/emails/%s",
+	"whois_nameservers": "/whois/nameservers/%s",
+	"pdns_domain":       "/pdns/domain/%s",
+	"pdns_name":         "/pdns/name/%s",
+	"pdns_ip":           "/pdns/ip/%s",
+	"pdns_raw":          "/pdns/raw/%s",
+	"pdns_timeline":     "/pdns/timeline/%s",
+	"risk_score":        "/domains/risk-score/%s",
+	"subdomains":        "/subdomains/%s",
+	"as_for_ip":         "/bgp_routes/ip/%s/as_for_ip.json",
+	"prefixes_for_asn":  "/bgp_routes/asn/%s/prefixes_for_asn.json",
+	"volume":            "/domains/volume/%s",
 }
 
 type Investigate struct {
-	client  *http.Client
-	key     string
-	log     *log.Logger
-	verbose bool
+	client    *http.Client
+	key       string
+	baseUrl   string
+	userAgent string
+	retry     RetryPolicy
+	retryHook func(RetryEvent)
+	limiter   *RateLimiter
+	quota     *Quota
+	cache     Cache
+	cacheTTLs map[string]time.Duration
+	cacheStat cacheCounters
+	// the number of concurrent requests made by bulk methods
+	concurrency  int
+	catBatchSize int
+	// whether responses keep their raw JSON, and how unknown fields in them
+	// are treated
+	keepRaw    bool
+	decodeMode DecodeMode
+	categories *CategoryRegistry
+	log        *log.Logger
+	verbose    bool
 }
 
 // Build a new Investigate client using an Investigate API key.
-func New(key string) *Investigate {
-	return &Investigate{
-		&http.Client{},
-		key,
-		log.New(os.Stdout, `[Investigate] `, 0),
-		false,
+// Any given Options are applied in order, e.g.:
+//
+//	inv := goinvestigate.New(key, goinvestigate.WithTimeout(10*time.Second))
+func New(key string, opts ...Option) *Investigate {
+	inv := &Investigate{
+		client:       &http.Client{},
+		key:          key,
+		baseUrl:      baseUrl,
+		retry:        DefaultRetryPolicy(),
+		concurrency:  defaultConcurrency,
+		catBatchSize: maxCategorizationBatch,
+		categories:   NewCategoryRegistry(nil),
+		log:          newDefaultLogger(),
 	}
+
+	for _, opt := range opts {
+		opt(inv)
+	}
+
+	return inv
+}
+
+// The logger used by clients which aren't given one with WithLogger.
+func newDefaultLogger() *log.Logger {
+	return log.New(os.Stdout, `[Investigate] `, 0)
 }
 
 // A generic Request method which makes the given request.
-// Will retry up to 5 times on failure.
+// Failed requests are retried according to the client's RetryPolicy, which
+// by default retries up to 5 times with exponential backoff.
 func (inv *Investigate) Request(req *http.Request) (*http.Response, error) {
+	return inv.RequestContext(context.Background(), req)
+}
+
+// Like Request, but the request is bound to ctx. Cancelling ctx aborts
+// the request in flight and stops any further retries, including one
+// which is waiting out its backoff.
+func (inv *Investigate) RequestContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", inv.key))
-	resp := new(http.Response)
-	var err error
-	tries := 0
+	if inv.userAgent != "" {
+		req.Header.Set("User-Agent", inv.userAgent)
+	}
+	start := time.Now()
 
-	for ; resp.Body == nil && tries <= maxTries; tries++ {
-		inv.Logf("%s %s\n", req.Method, req.URL.String())
-		resp, err = inv.client.Do(req)
-		if err != nil || (resp.StatusCode >= 400 && resp.StatusCode < 600) {
-			// if it's a 400 error code, just return an error.
-			// otherwise, if it's a server error, retry
-			if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-				errStr := fmt.Sprintf("error: %v", err)
-				inv.Log(errStr)
-				inv.LogHTTPResponseBody(resp.Body)
-				return nil, errors.New(errStr)
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		if inv.limiter != nil {
+			if err := inv.limiter.Wait(ctx); err != nil {
+				return nil, err
 			}
+		}
+
+		// only counted once the request is sure to be sent
+		if inv.quota != nil {
+			if err := inv.quota.take(inv.requestEndpoint(req)); err != nil {
+				inv.Log(err.Error())
+				return nil, err
+			}
+		}
 
-			if tries == maxTries {
-				errStr := fmt.Sprintf("error: %v\nFailed all attempts. Skipping.", err)
-				log.Print(errStr)
-				return nil, errors.New(errStr)
+		inv.Logf("%s %s\n", req.Method, req.URL.String())
+		resp, err := inv.client.Do(attemptReq)
+
+		// the transport error was caused by the context, so don't retry
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if err == nil && resp.StatusCode < 400 {
+			return resp, nil
+		}
+
+		// the policy has to see the response before its body is consumed
+		wait, retry := inv.retry.Retry(attempt, time.Since(start), resp, err)
+		retry = retry && rewindable(req)
+		if err == nil {
+			err = newAPIError(req, resp)
+		}
+
+		if !retry {
+			if attempt > 1 {
+				inv.log.Printf("error: %v\nFailed all %d attempts. Skipping.", err, attempt)
+			} else {
+				inv.Log(err.Error())
 			}
+			return nil, err
+		}
 
-			log.Printf("\nerror: %v\nTrying again: Attempt %d/%d\n", err, tries+1, maxTries)
-			resp = new(http.Response)
+		inv.log.Printf("\nerror: %v\nTrying again in %v: Attempt %d\n", err, wait, attempt+1)
+		if inv.retryHook != nil {
+			inv.retryHook(RetryEvent{
+				Request: req,
+				Attempt: attempt,
+				Wait:    wait,
+				Err:     err,
+			})
 		}
-	}
 
-	return resp, err
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
 }
 
 // A generic GET call to the Investigate API.
-// Will make an HTTP request to: https://investigate.api.opendns.com{subUri}
+// Will make an HTTP request to: https://investigate.api.opendns.com{subUri},
+// or to the base URL given with WithBaseURL.
 func (inv *Investigate) Get(subUri string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", baseUrl+subUri, nil)
+	return inv.GetContext(context.Background(), subUri)
+}
+
+// Like Get, but the request is bound to ctx.
+func (inv *Investigate) GetContext(ctx context.Context, subUri string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", inv.baseUrl+subUri, nil)
 
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("Error processing GET request: %v", err))
 	}
 
-	return inv.Request(req)
+	return inv.RequestContext(ctx, req)
 }
 
 // A generic POST call, which forms a request with the given body
 func (inv *Investigate) Post(subUri string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest("POST", baseUrl+subUri, body)
+	return inv.PostContext(context.Background(), subUri, body)
+}
+
+// Like Post, but the request is bound to ctx.
+func (inv *Investigate) PostContext(ctx context.Context, subUri string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest("POST", inv.baseUrl+subUri, body)
 
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("Error processing POST request: %v", err))
 	}
 
-	return inv.Request(req)
+	return inv.RequestContext(ctx, req)
 }
 
 func catUri(domain string, labels bool) (string, error) {
@@ -158,18 +279,27 @@ func catUri(domain string, labels bool) (string, error) {
 //
 // For more detail, see https://sgraph.opendns.com/docs/api#categorization
 func (inv *Investigate) Categorization(domain string, labels bool) (*DomainCategorization, error) {
-	uri, err := catUri(domain, labels)
+	return inv.CategorizationContext(context.Background(), domain, labels)
+}
+
+// Like Categorization, but the request is bound to ctx.
+func (inv *Investigate) CategorizationContext(ctx context.Context, domain string, labels bool) (*DomainCategorization, error) {
+	domain, err := NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := catUri(url.PathEscape(domain), labels)
 	if err != nil {
 		inv.Logf("%v", err)
 		return nil, err
 	}
 	resp := make(map[string]DomainCategorization)
-	err = inv.GetParse(uri, resp)
+	err = inv.GetParseContext(ctx, uri, &resp)
 	if err != nil {
 		return nil, err
 	}
 	if cat, ok := resp[domain]; !ok {
-		return nil, errors.New("received a malformed response body")
+		return nil, ErrMalformedResponse
 	} else {
 		return &cat, nil
 	}
@@ -178,13 +308,89 @@ func (inv *Investigate) Categorization(domain string, labels bool) (*DomainCateg
 // Get the status and categorization of a list of domains
 // Setting 'labels' to true will give back categorizations in human-readable form.
 //
+// Long lists are split into chunks of up to 1000 domains (see
+// WithCategorizationBatchSize), which are sent concurrently. If some of the
+// chunks fail, the categorizations from the others are still returned,
+// along with a *ChunkError listing the domains which weren't categorized.
+// This is the case even when the domains fit in a single chunk. Invalid
+// domains aren't sent, and each is listed in the *ChunkError on its own.
+//
+// The domains are normalized before they're sent (see NormalizeDomain), and
+// the result is keyed by the normalized names, e.g. "a.com" for "A.com.".
+// Domains which normalize to the same name are only sent once.
+//
 // For more detail, see https://sgraph.opendns.com/docs/api#categorization
 func (inv *Investigate) Categorizations(domains []string, labels bool) (map[string]DomainCategorization, error) {
+	return inv.CategorizationsContext(context.Background(), domains, labels)
+}
+
+// Like Categorizations, but the requests are bound to ctx.
+func (inv *Investigate) CategorizationsContext(ctx context.Context, domains []string, labels bool) (map[string]DomainCategorization, error) {
 	uri, err := catUri("", labels)
 	if err != nil {
 		inv.Logf("%v", err)
 		return nil, err
 	}
+
+	// invalid domains fail on their own, without holding up the others
+	domains, failures := normalizeDomains(domains)
+	if len(domains) == 0 {
+		// nothing is left to ask the API about
+		if len(failures) > 0 {
+			return make(map[string]DomainCategorization), &ChunkError{failures}
+		}
+		return make(map[string]DomainCategorization), nil
+	}
+
+	// even a single chunk goes through here, so that failures always come
+	// back as a *ChunkError
+	chunks := chunkStrings(domains, inv.catBatchSize)
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		resp = make(map[string]DomainCategorization, len(domains))
+		sem  = make(chan struct{}, inv.concurrency)
+		// kept by chunk, so that failures are in the same order every time
+		chunkErrs = make([]error, len(chunks))
+	)
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			chunkResp, err := inv.categorizeChunk(ctx, uri, chunk)
+			if err != nil {
+				chunkErrs[i] = err
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for domain, cat := range chunkResp {
+				resp[domain] = cat
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	// invalid domains come first, then the chunks in the order they were sent
+	for i, err := range chunkErrs {
+		if err != nil {
+			failures = append(failures, ChunkFailure{chunks[i], err})
+		}
+	}
+
+	if len(failures) > 0 {
+		return resp, &ChunkError{failures}
+	}
+
+	return resp, nil
+}
+
+// Categorizes the given domains with a single request.
+func (inv *Investigate) categorizeChunk(ctx context.Context, uri string, domains []string) (map[string]DomainCategorization, error) {
 	body, err := json.Marshal(domains)
 
 	if err != nil {
@@ -193,7 +399,7 @@ func (inv *Investigate) Categorizations(domains []string, labels bool) (map[stri
 	}
 
 	resp := make(map[string]DomainCategorization)
-	err = inv.PostParse(uri, bytes.NewReader(body), resp)
+	err = inv.PostParseContext(ctx, uri, bytes.NewReader(body), &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -201,16 +407,58 @@ func (inv *Investigate) Categorizations(domains []string, labels bool) (map[stri
 	return resp, nil
 }
 
+// Splits items into chunks of at most size items each. There are no chunks
+// if there are no items.
+func chunkStrings(items []string, size int) [][]string {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var chunks [][]string
+	for size > 0 && len(items) > size {
+		chunks = append(chunks, items[:size:size])
+		items = items[size:]
+	}
+	return append(chunks, items)
+}
+
 // Use domain to make the HTTP request: /links/name/{domain}.json
 // Get the related domains of the given domain.
 //
 // For details, see https://sgraph.opendns.com/docs/api#relatedDomains
 func (inv *Investigate) RelatedDomains(domain string) ([]RelatedDomain, error) {
-	var resp RelatedDomainList
-	err := inv.GetParse(fmt.Sprintf(urls["related"], domain), &resp)
+	return inv.RelatedDomainsContext(context.Background(), domain)
+}
+
+// Like RelatedDomains, but the request is bound to ctx.
+func (inv *Investigate) RelatedDomainsContext(ctx context.Context, domain string) ([]RelatedDomain, error) {
+	resp, err := inv.RelatedDomainsResultContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	return RelatedDomainList(resp.RelatedDomains), nil
+}
+
+// Like RelatedDomains, but returns the whole response, including whether
+// the domain was found and any fields unknown to RelatedDomainsResult.
+func (inv *Investigate) RelatedDomainsResult(domain string) (*RelatedDomainsResult, error) {
+	return inv.RelatedDomainsResultContext(context.Background(), domain)
+}
+
+// Like RelatedDomainsResult, but the request is bound to ctx.
+func (inv *Investigate) RelatedDomainsResultContext(ctx context.Context, domain string) (*RelatedDomainsResult, error) {
+	segment, err := domainSegment(domain)
 	if err != nil {
 		return nil, err
 	}
+	resp := new(RelatedDomainsResult)
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["related"], segment), resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.RelatedDomains == nil {
+		resp.RelatedDomains = []RelatedDomain{}
+	}
 	return resp, nil
 }
 
@@ -218,11 +466,38 @@ func (inv *Investigate) RelatedDomains(domain string) ([]RelatedDomain, error) {
 //
 // For details, see https://sgraph.opendns.com/docs/api#co-occurrences
 func (inv *Investigate) Cooccurrences(domain string) ([]Cooccurrence, error) {
-	var resp CooccurrenceList
-	err := inv.GetParse(fmt.Sprintf(urls["cooccurrences"], domain), &resp)
+	return inv.CooccurrencesContext(context.Background(), domain)
+}
+
+// Like Cooccurrences, but the request is bound to ctx.
+func (inv *Investigate) CooccurrencesContext(ctx context.Context, domain string) ([]Cooccurrence, error) {
+	resp, err := inv.CooccurrencesResultContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	return CooccurrenceList(resp.Cooccurrences), nil
+}
+
+// Like Cooccurrences, but returns the whole response, including whether
+// the domain was found and any fields unknown to CooccurrencesResult.
+func (inv *Investigate) CooccurrencesResult(domain string) (*CooccurrencesResult, error) {
+	return inv.CooccurrencesResultContext(context.Background(), domain)
+}
+
+// Like CooccurrencesResult, but the request is bound to ctx.
+func (inv *Investigate) CooccurrencesResultContext(ctx context.Context, domain string) (*CooccurrencesResult, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
+	resp := new(CooccurrencesResult)
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["cooccurrences"], segment), resp)
 	if err != nil {
 		return nil, err
 	}
+	if resp.Cooccurrences == nil {
+		resp.Cooccurrences = []Cooccurrence{}
+	}
 	return resp, nil
 }
 
@@ -230,8 +505,17 @@ func (inv *Investigate) Cooccurrences(domain string) ([]Cooccurrence, error) {
 //
 // For details, see https://sgraph.opendns.com/docs/api#securityInfo
 func (inv *Investigate) Security(domain string) (*SecurityFeatures, error) {
+	return inv.SecurityContext(context.Background(), domain)
+}
+
+// Like Security, but the request is bound to ctx.
+func (inv *Investigate) SecurityContext(ctx context.Context, domain string) (*SecurityFeatures, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
 	resp := new(SecurityFeatures)
-	err := inv.GetParse(fmt.Sprintf(urls["security"], domain), resp)
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["security"], segment), resp)
 	if err != nil {
 		return nil, err
 	}
@@ -242,33 +526,44 @@ func (inv *Investigate) Security(domain string) (*SecurityFeatures, error) {
 //
 // For details, see https://sgraph.opendns.com/docs/api#latest_tags
 func (inv *Investigate) DomainTags(domain string) ([]DomainTag, error) {
+	return inv.DomainTagsContext(context.Background(), domain)
+}
+
+// Like DomainTags, but the request is bound to ctx.
+func (inv *Investigate) DomainTagsContext(ctx context.Context, domain string) ([]DomainTag, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
 	var resp []DomainTag
-	err := inv.GetParse(fmt.Sprintf(urls["tags"], domain), &resp)
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["tags"], segment), &resp)
 	if err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func queryTypeSupported(qType string) bool {
-	_, ok := supportedQueryTypes[qType]
-	return ok
-}
-
 // Get the RR (Resource Record) History of the given IP.
-// queryType is the type of DNS query to perform on the database.
-// The following query types are supported:
-//
-// A, NS, MX, TXT, CNAME
+// queryType is the type of DNS query to perform on the database; see
+// SupportedQueryTypes for the ones which can be used.
 //
 // For details, see https://sgraph.opendns.com/docs/api#dnsrr_ip
-func (inv *Investigate) IpRRHistory(ip string, queryType string) (*IPRRHistory, error) {
+func (inv *Investigate) IpRRHistory(ip string, queryType QueryType) (*IPRRHistory, error) {
+	return inv.IpRRHistoryContext(context.Background(), ip, queryType)
+}
+
+// Like IpRRHistory, but the request is bound to ctx.
+func (inv *Investigate) IpRRHistoryContext(ctx context.Context, ip string, queryType QueryType) (*IPRRHistory, error) {
 	// If the user tried an unsupported query type, return an error
 	if !queryTypeSupported(queryType) {
-		return nil, errors.New("unsupported query type")
+		return nil, ErrUnsupportedQueryType
+	}
+	segment, err := ipSegment(ip)
+	if err != nil {
+		return nil, err
 	}
 	resp := new(IPRRHistory)
-	err := inv.GetParse(fmt.Sprintf(urls["ip"], queryType, ip), resp)
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["ip"], queryType, segment), resp)
 	if err != nil {
 		return nil, err
 	}
@@ -276,25 +571,52 @@ func (inv *Investigate) IpRRHistory(ip string, queryType string) (*IPRRHistory,
 }
 
 // Get the RR (Resource Record) History of the given domain.
-// queryType is the type of DNS query to perform on the database.
-// The following query types are supported:
-//
-// A, NS, MX, TXT, CNAME
+// queryType is the type of DNS query to perform on the database; see
+// SupportedQueryTypes for the ones which can be used.
 //
 // For details, see https://sgraph.opendns.com/docs/api#dnsrr_domain
-func (inv *Investigate) DomainRRHistory(domain string, queryType string) (*DomainRRHistory, error) {
+func (inv *Investigate) DomainRRHistory(domain string, queryType QueryType) (*DomainRRHistory, error) {
+	return inv.DomainRRHistoryContext(context.Background(), domain, queryType)
+}
+
+// Like DomainRRHistory, but the request is bound to ctx.
+func (inv *Investigate) DomainRRHistoryContext(ctx context.Context, domain string, queryType QueryType) (*DomainRRHistory, error) {
 	// If the user tried an unsupported query type, return an error
 	if !queryTypeSupported(queryType) {
-		return nil, errors.New("unsupported query type")
+		return nil, ErrUnsupportedQueryType
+	}
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
 	}
 	resp := new(DomainRRHistory)
-	err := inv.GetParse(fmt.Sprintf(urls["domain"], queryType, domain), resp)
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["domain"], queryType, segment), resp)
 	if err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
+// Normalizes each of the domains, returning the valid ones without
+// duplicates, along with a failure for each invalid one.
+func normalizeDomains(domains []string) ([]string, []ChunkFailure) {
+	normalized := make([]string, 0, len(domains))
+	seen := make(map[string]bool, len(domains))
+	var failures []ChunkFailure
+	for _, domain := range domains {
+		n, err := NormalizeDomain(domain)
+		if err != nil {
+			failures = append(failures, ChunkFailure{[]string{domain}, err})
+			continue
+		}
+		if !seen[n] {
+			seen[n] = true
+			normalized = append(normalized, n)
+		}
+	}
+	return normalized, failures
+}
+
 func extractDomains(respList []MaliciousDomain) []string {
 	var domainList []string
 	for _, entry := range respList {
@@ -308,8 +630,17 @@ func extractDomains(respList []MaliciousDomain) []string {
 //
 // For details, see https://sgraph.opendns.com/docs/api#latest_domains
 func (inv *Investigate) LatestDomains(ip string) ([]string, error) {
+	return inv.LatestDomainsContext(context.Background(), ip)
+}
+
+// Like LatestDomains, but the request is bound to ctx.
+func (inv *Investigate) LatestDomainsContext(ctx context.Context, ip string) ([]string, error) {
+	segment, err := ipSegment(ip)
+	if err != nil {
+		return nil, err
+	}
 	var resp []MaliciousDomain
-	err := inv.GetParse(fmt.Sprintf(urls["latest_domains"], ip), &resp)
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["latest_domains"], segment), &resp)
 
 	if err != nil {
 		return nil, err
@@ -318,27 +649,23 @@ func (inv *Investigate) LatestDomains(ip string) ([]string, error) {
 	return extractDomains(resp), nil
 }
 
-// Converts the given list of items (domains or IPs)
-// to a list of their appropriate URIs for the Investigate API
-func convertToSubUris(items []string, queryType string) []string {
-	subUris := make([]string, len(items))
-	for i, item := range items {
-		subUris[i] = fmt.Sprintf(urls[queryType], item)
-	}
-	return subUris
-}
-
 // Convenience function to perform Get and parse the response body.
 // Parses the response into the value pointed to by v.
+// If the client has a Cache, the response may come from it.
 func (inv *Investigate) GetParse(subUri string, v interface{}) error {
-	resp, err := inv.Get(subUri)
+	return inv.GetParseContext(context.Background(), subUri, v)
+}
+
+// Like GetParse, but the request is bound to ctx.
+func (inv *Investigate) GetParseContext(ctx context.Context, subUri string, v interface{}) error {
+	respBody, err := inv.cachedRequest(ctx, "GET", subUri, nil)
 
 	if err != nil {
 		inv.Log(err.Error())
 		return err
 	}
 
-	err = inv.parseBody(resp.Body, v)
+	err = inv.parseBody(respBody, v)
 
 	if err != nil && inv.verbose {
 		inv.Log(err.Error())
@@ -349,15 +676,21 @@ func (inv *Investigate) GetParse(subUri string, v interface{}) error {
 
 // Convenience function to perform Post and parse the response body.
 // Parses the response into the value pointed to by v.
+// If the client has a Cache, the response may come from it.
 func (inv *Investigate) PostParse(subUri string, body io.Reader, v interface{}) error {
-	resp, err := inv.Post(subUri, body)
+	return inv.PostParseContext(context.Background(), subUri, body, v)
+}
+
+// Like PostParse, but the request is bound to ctx.
+func (inv *Investigate) PostParseContext(ctx context.Context, subUri string, body io.Reader, v interface{}) error {
+	respBody, err := inv.cachedRequest(ctx, "POST", subUri, body)
 
 	if err != nil {
 		inv.Log(err.Error())
 		return err
 	}
 
-	err = inv.parseBody(resp.Body, v)
+	err = inv.parseBody(respBody, v)
 
 	if err != nil {
 		inv.Log(err.Error())
@@ -366,41 +699,75 @@ func (inv *Investigate) PostParse(subUri string, body io.Reader, v interface{})
 	return err
 }
 
-// Parse an HTTP JSON response into a map
+// Perform a GET request to the Investigate API and decode the JSON response
+// into a T. Use it for endpoints this package doesn't wrap yet, e.g.:
+//
+//	type whois struct {
+//		Registrant string `json:"registrantName"`
+//	}
+//	w, err := goinvestigate.GetJSON[whois](ctx, inv, "/whois/example.com")
+func GetJSON[T any](ctx context.Context, inv *Investigate, subUri string) (T, error) {
+	var v T
+	err := inv.GetParseContext(ctx, subUri, &v)
+	return v, err
+}
+
+// Perform a POST request to the Investigate API with the given body, and
+// decode the JSON response into a T.
+func PostJSON[T any](ctx context.Context, inv *Investigate, subUri string, body io.Reader) (T, error) {
+	var v T
+	err := inv.PostParseContext(ctx, subUri, body, &v)
+	return v, err
+}
+
+// Parse an HTTP JSON response into the value pointed to by v
 func (inv *Investigate) parseBody(respBody io.ReadCloser, v interface{}) (err error) {
 	defer respBody.Close()
 	body, err := ioutil.ReadAll(respBody)
 	if err != nil {
-		log.Printf("error reading body: %v", err)
+		inv.log.Printf("error reading body: %v", err)
 		return err
 	}
 
-	switch unpackedValue := v.(type) {
-	case *CooccurrenceList:
-		err = json.Unmarshal(body, unpackedValue)
-	case *RelatedDomainList:
-		err = json.Unmarshal(body, unpackedValue)
-	case *[]MaliciousDomain:
-		err = json.Unmarshal(body, unpackedValue)
-	case map[string]DomainCategorization:
-		err = json.Unmarshal(body, &unpackedValue)
-	case *SecurityFeatures:
-		err = json.Unmarshal(body, unpackedValue)
-	case *[]DomainTag:
-		err = json.Unmarshal(body, unpackedValue)
-	case *DomainRRHistory:
-		err = json.Unmarshal(body, unpackedValue)
-	case *IPRRHistory:
-		err = json.Unmarshal(body, unpackedValue)
-	default:
-		err = errors.New("type of v is unsupported")
+	// maps used to be accepted by value, so keep decoding into them in place
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map && !rv.IsNil() {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		v = ptr.Interface()
+	}
+
+	err = json.Unmarshal(body, v)
+
+	// v isn't a non-nil pointer, which is the caller's mistake rather than
+	// the response's
+	var invalidErr *json.InvalidUnmarshalError
+	if errors.As(err, &invalidErr) {
+		return err
 	}
 
 	if err != nil {
 		inv.Logf("error unmarshaling JSON response: %v\nbody: %s", err, body)
+		return fmt.Errorf("%w: %w", ErrMalformedResponse, err)
 	}
 
-	return err
+	if inv.keepRaw {
+		attachRaw(v, body)
+	}
+
+	if inv.decodeMode == DecodeLenient {
+		return nil
+	}
+
+	fields := unknownFields(v)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if inv.decodeMode == DecodeStrict {
+		return &SchemaDriftError{Fields: fields}
+	}
+	inv.log.Printf("response has unknown fields: %s", strings.Join(fields, ", "))
+	return nil
 }
 
 // Log something to stdout
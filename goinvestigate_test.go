@@ -1,29 +1,141 @@
 package goinvestigate
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/dead10ck/goinvestigate/goinvestigatetest"
 )
 
 var (
-	key string
-	inv *Investigate
+	key     string
+	inv     *Investigate
+	verbose = flag.Bool("sgverbose", false, "Set SGraph output to verbose.")
+	record  = flag.Bool("record", false, "Record live API exchanges to "+fixtureDir+".")
+
+	// options for every client made by the tests, which point them at the
+	// local test server when there's no API key
+	testOpts []Option
+	srv      *goinvestigatetest.Server
 )
 
 func init() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
-	verbose := flag.Bool("sgverbose", false, "Set SGraph output to verbose.")
+}
+
+// where the exchanges recorded with -record are kept
+const fixtureDir = "testdata/fixtures"
+
+// flags can only be parsed once the testing package has registered its own,
+// so the client is set up here rather than in init()
+//
+// With INVESTIGATE_KEY, the tests run against the live API, and -record saves
+// the exchanges to testdata/fixtures. Without it, they replay the recorded
+// exchanges if there are any, or run against a goinvestigatetest.Server.
+func TestMain(m *testing.M) {
 	flag.Parse()
 	key := os.Getenv("INVESTIGATE_KEY")
-	if key == "" {
-		log.Fatal("INVESTIGATE_KEY environment variable not set")
+	fixtures, _ := filepath.Glob(filepath.Join(fixtureDir, "*.json"))
+
+	switch {
+	case key != "" && *record:
+		testOpts = recordOpts(fixtureDir)
+	case key == "" && len(fixtures) > 0:
+		log.Print("INVESTIGATE_KEY environment variable not set, replaying ", fixtureDir)
+		key = "replay_key"
+		testOpts = replayOpts(fixtureDir)
+	case key == "":
+		log.Print("INVESTIGATE_KEY environment variable not set, testing against a local server")
+		srv = goinvestigatetest.NewServer()
+		key = srv.Key
+		testOpts = append(testOpts, WithBaseURL(srv.URL))
 	}
-	inv = New(key)
+	inv = New(key, testOpts...)
 	inv.SetVerbose(*verbose)
+	code := m.Run()
+	if srv != nil {
+		srv.Close()
+	}
+	os.Exit(code)
+}
+
+// Options for a client which records its exchanges to dir.
+func recordOpts(dir string) []Option {
+	rec := goinvestigatetest.NewRecorder(dir, goinvestigatetest.ModeRecord, nil)
+	return []Option{WithTransport(rec)}
+}
+
+// Options for a client which replays the exchanges recorded in dir.
+func replayOpts(dir string) []Option {
+	rec := goinvestigatetest.NewRecorder(dir, goinvestigatetest.ModeReplay, nil)
+	// a missing fixture won't turn up by trying again
+	return []Option{WithTransport(rec), WithRetryPolicy(&ExponentialBackoff{MaxAttempts: 1})}
+}
+
+// Fixtures aren't committed, since they can only be recorded with an API key,
+// so the replay mode of TestMain is checked here with exchanges recorded from
+// a local server.
+func TestReplay(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	srv := goinvestigatetest.NewServer()
+	recInv := New(srv.Key, append(recordOpts(dir), WithBaseURL(srv.URL))...)
+
+	if _, err := recInv.Categorization("www.amazon.com", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recInv.Categorizations([]string{"www.amazon.com", "bibikun.ru"}, true); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	// the server is gone, so everything has to come from the fixtures
+	replayInv := New("replay_key", append(replayOpts(dir), WithLogger(log.New(ioutil.Discard, "", 0)))...)
+
+	out, err := replayInv.Categorization("www.amazon.com", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Status != 1 || len(out.ContentCategories) != 1 || out.ContentCategories[0] != "8" {
+		t.Fatalf("unexpected replayed categorization %+v", out)
+	}
+
+	outs, err := replayInv.Categorizations([]string{"www.amazon.com", "bibikun.ru"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if outs["bibikun.ru"].Status != -1 || outs["www.amazon.com"].ContentCategories[0] != "Ecommerce/Shopping" {
+		t.Fatalf("unexpected replayed categorizations %v", outs)
+	}
+
+	if _, err := replayInv.Security("www.test.com"); !errors.Is(err, goinvestigatetest.ErrNoFixture) {
+		t.Fatalf("%v should be %v", err, goinvestigatetest.ErrNoFixture)
+	}
+}
+
+// A client for a goinvestigatetest.Server of its own, for tests which check
+// canned responses. The server is closed when the test ends.
+func newTestClient(t *testing.T, opts ...Option) (*Investigate, *goinvestigatetest.Server) {
+	t.Helper()
+	srv := goinvestigatetest.NewServer()
+	t.Cleanup(srv.Close)
+	return New(srv.Key, append([]Option{WithBaseURL(srv.URL)}, opts...)...), srv
 }
 
 func TestIPRRHistory(t *testing.T) {
@@ -138,6 +250,165 @@ func TestCategorizations(t *testing.T) {
 	}
 }
 
+func TestCategorizationsChunked(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var batches [][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var domains []string
+		json.NewDecoder(r.Body).Decode(&domains)
+
+		mu.Lock()
+		batches = append(batches, domains)
+		mu.Unlock()
+
+		for _, d := range domains {
+			if d == "bad.com" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		resp := make(map[string]DomainCategorization)
+		for _, d := range domains {
+			resp[d] = DomainCategorization{Status: 1}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	chunkInv := New("test_key", WithBaseURL(ts.URL), WithCategorizationBatchSize(2))
+	domains := []string{"a.com", "b.com", "c.com", "bad.com", "d.com"}
+	out, err := chunkInv.Categorizations(domains, false)
+
+	if len(batches) != 3 {
+		t.Fatalf("sent %d requests, should have sent 3: %v", len(batches), batches)
+	}
+
+	for _, batch := range batches {
+		if len(batch) > 2 {
+			t.Fatalf("sent a batch of %d domains: %v", len(batch), batch)
+		}
+	}
+
+	var chunkErr *ChunkError
+	if !errors.As(err, &chunkErr) || len(chunkErr.Failures) != 1 {
+		t.Fatalf("%v should be a *ChunkError with one failure", err)
+	}
+
+	if !strSliceEq(chunkErr.Failures[0].Domains, []string{"c.com", "bad.com"}) {
+		t.Fatalf("the wrong chunk failed: %v", chunkErr.Failures[0].Domains)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("%v should wrap the chunk's *APIError", err)
+	}
+
+	if len(out) != 3 || out["a.com"].Status != 1 || out["b.com"].Status != 1 || out["d.com"].Status != 1 {
+		t.Fatalf("unexpected categorizations %v", out)
+	}
+}
+
+func TestCategorizationsSingleChunkError(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	chunkInv := New("test_key", WithBaseURL(ts.URL))
+	_, err := chunkInv.Categorizations([]string{"a.com", "b.com"}, false)
+
+	var chunkErr *ChunkError
+	if !errors.As(err, &chunkErr) || len(chunkErr.Failures) != 1 || len(chunkErr.Failures[0].Domains) != 2 {
+		t.Fatalf("%v should be a *ChunkError with one failure", err)
+	}
+}
+
+func TestCategorizationsFailureOrder(t *testing.T) {
+	t.Parallel()
+	delays := map[string]time.Duration{"a.com": 30 * time.Millisecond, "b.com": 15 * time.Millisecond}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var domains []string
+		json.NewDecoder(r.Body).Decode(&domains)
+		// the later chunks finish first
+		time.Sleep(delays[domains[0]])
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	chunkInv := New("test_key", WithBaseURL(ts.URL), WithCategorizationBatchSize(1))
+	_, err := chunkInv.Categorizations([]string{"a.com", "b.com", "bad domain", "c.com"}, false)
+
+	var chunkErr *ChunkError
+	if !errors.As(err, &chunkErr) || len(chunkErr.Failures) != 4 {
+		t.Fatalf("%v should be a *ChunkError with four failures", err)
+	}
+
+	for i, ref := range []string{"bad domain", "a.com", "b.com", "c.com"} {
+		if !strSliceEq(chunkErr.Failures[i].Domains, []string{ref}) {
+			t.Fatalf("failure %d is for %v, should be for %s", i, chunkErr.Failures[i].Domains, ref)
+		}
+	}
+
+	if !errors.Is(chunkErr.Failures[0].Err, ErrInvalidInput) || !strings.Contains(err.Error(), "bad domain") {
+		t.Fatalf("%v should lead with the invalid domain", err)
+	}
+}
+
+func TestCategorizationsEmpty(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	chunkInv := New("test_key", WithBaseURL(ts.URL))
+	for _, domains := range [][]string{nil, {}} {
+		out, err := chunkInv.Categorizations(domains, false)
+		if err != nil || out == nil || len(out) != 0 {
+			t.Fatalf("got %v, %v; should get an empty map", out, err)
+		}
+	}
+
+	if requests != 0 {
+		t.Fatalf("made %d requests, should have made none", requests)
+	}
+}
+
+func TestCategorizationsInvalidDomains(t *testing.T) {
+	t.Parallel()
+	var sent []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"a.com": {"status": 1}, "b.com": {"status": -1}}`))
+	}))
+	defer ts.Close()
+
+	chunkInv := New("test_key", WithBaseURL(ts.URL))
+	out, err := chunkInv.Categorizations([]string{"A.com", "bad domain", "b.com", "a.com."}, false)
+
+	if !strSliceEq(sent, []string{"a.com", "b.com"}) {
+		t.Fatalf("sent %v, should only send the valid domains", sent)
+	}
+
+	var chunkErr *ChunkError
+	if !errors.As(err, &chunkErr) || len(chunkErr.Failures) != 1 || !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("%v should be a *ChunkError for the invalid domain", err)
+	}
+
+	if !strSliceEq(chunkErr.Failures[0].Domains, []string{"bad domain"}) {
+		t.Fatalf("the wrong domain failed: %v", chunkErr.Failures[0].Domains)
+	}
+
+	if len(out) != 2 || out["b.com"].Status != StatusMalicious {
+		t.Fatalf("unexpected categorizations %v", out)
+	}
+}
+
 func TestRelatedDomains(t *testing.T) {
 	t.Parallel()
 	out, err := inv.RelatedDomains("www.test.com")
@@ -178,7 +449,7 @@ func TestDomainTags(t *testing.T) {
 		t.Fatal(err)
 	}
 	if len(out) <= 0 {
-		t.Fatal(fmt.Sprintf("%v should not be empty"), out)
+		t.Fatal(fmt.Sprintf("%v should not be empty", out))
 	}
 }
 
@@ -197,7 +468,7 @@ func TestLatestDomains(t *testing.T) {
 
 func TestErrorResponse(t *testing.T) {
 	t.Parallel()
-	badInv := New("bad_key")
+	badInv := New("bad_key", testOpts...)
 	badInv.SetVerbose(true)
 	_, err := badInv.Categorization("www.google.com", true)
 
@@ -206,3 +477,83 @@ func TestErrorResponse(t *testing.T) {
 		t.Fatal("should return an authentication error")
 	}
 }
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRequestContextCanceled(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	ctxInv := New("test_key")
+	ctxInv.client = &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return nil, req.Context().Err()
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ctxInv.SecurityContext(ctx, "www.test.com")
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("%v should be %v", err, context.Canceled)
+	}
+
+	if calls != 0 {
+		t.Fatalf("made %d requests with a canceled context", calls)
+	}
+}
+
+func TestGetJSON(t *testing.T) {
+	t.Parallel()
+	jsonInv, jsonSrv := newTestClient(t)
+	jsonSrv.SetResponse("/whois/example.com", `{"registrantName": "Example Org", "nameServers": ["a.iana-servers.net"]}`)
+
+	type whois struct {
+		Registrant  string   `json:"registrantName"`
+		NameServers []string `json:"nameServers"`
+	}
+
+	out, err := GetJSON[whois](context.Background(), jsonInv, "/whois/example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Registrant != "Example Org" || len(out.NameServers) != 1 {
+		t.Fatalf("unexpected response %+v", out)
+	}
+
+	cats, err := PostJSON[map[string]DomainCategorization](context.Background(), jsonInv,
+		"/domains/categorization/?showLabels=true", strings.NewReader(`["bibikun.ru"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cats["bibikun.ru"].SecurityCategories[0] != "Malware" {
+		t.Fatalf("unexpected response %v", cats)
+	}
+
+	_, err = GetJSON[whois](context.Background(), jsonInv, "/security/name/www.test.com.json/nope")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("%v should be %v", err, ErrNotFound)
+	}
+}
+
+func TestGetParseMap(t *testing.T) {
+	t.Parallel()
+	jsonInv, _ := newTestClient(t)
+
+	// maps can still be passed by value
+	resp := make(map[string]DomainCategorization)
+	if err := jsonInv.GetParse("/domains/categorization/www.amazon.com", resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp["www.amazon.com"].Status != 1 {
+		t.Fatalf("unexpected response %v", resp)
+	}
+}
@@ -0,0 +1,1050 @@
+/*
+Package goinvestigatefake provides an in-memory implementation of
+goinvestigate.Investigator, for testing code which uses the Investigate API
+without making live calls.
+
+Seed the fake with the responses the code under test should see:
+
+	inv := goinvestigatefake.New()
+	inv.AddSecurity("bibikun.ru", &goinvestigate.SecurityFeatures{DGAScore: -3.5})
+	inv.AddError("www.test.com", goinvestigate.ErrRateLimited)
+
+	svc := NewEnrichmentService(inv)
+
+Queries for anything which wasn't seeded fail with a 404 *goinvestigate.APIError,
+which matches goinvestigate.ErrNotFound.
+
+Domains and IPs are normalized as the real client normalizes them, both when
+they're seeded and when they're queried, so "WWW.Test.com." finds what was
+seeded for "www.test.com". Invalid input, such as a malformed domain, email
+address or ASN, fails with a *goinvestigate.ValidationError, as it does with
+the real client.
+*/
+package goinvestigatefake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dead10ck/goinvestigate"
+)
+
+// Investigator is a fake goinvestigate.Investigator which answers queries
+// from the data it was seeded with. It is safe for concurrent use.
+type Investigator struct {
+	mu              sync.RWMutex
+	categorizations map[string]goinvestigate.DomainCategorization
+	related         map[string][]goinvestigate.RelatedDomain
+	cooccurrences   map[string][]goinvestigate.Cooccurrence
+	security        map[string]*goinvestigate.SecurityFeatures
+	tags            map[string][]goinvestigate.DomainTag
+	ipRRHistory     map[rrKey]*goinvestigate.IPRRHistory
+	domainRRHistory map[rrKey]*goinvestigate.DomainRRHistory
+	latestDomains   map[string][]string
+	categories      map[string]string
+	whois           map[string]*goinvestigate.WhoisRecord
+	whoisHistory    map[string][]goinvestigate.WhoisRecord
+	whoisByEmail    map[string][]goinvestigate.WhoisDomain
+	whoisByNS       map[string][]goinvestigate.WhoisDomain
+	pdns            map[pdnsKey][]goinvestigate.PDNSRecord
+	pdnsTimeline    map[string][]goinvestigate.PDNSTimelineEntry
+	riskScores      map[string]*goinvestigate.DomainRiskScore
+	subdomains      map[string][]goinvestigate.Subdomain
+	asForIP         map[string][]goinvestigate.ASInfo
+	asnPrefixes     map[int][]goinvestigate.ASPrefix
+	volumes         map[string]*goinvestigate.DomainVolume
+	errs            map[string]error
+}
+
+// the passive DNS records of an item from one of the endpoints, e.g.
+// {"ip", "1.2.3.4"}
+type pdnsKey struct {
+	endpoint string
+	item     string
+}
+
+type rrKey struct {
+	item      string
+	queryType goinvestigate.QueryType
+}
+
+var _ goinvestigate.Investigator = (*Investigator)(nil)
+
+// Build an empty fake Investigator.
+func New() *Investigator {
+	return &Investigator{
+		categorizations: make(map[string]goinvestigate.DomainCategorization),
+		related:         make(map[string][]goinvestigate.RelatedDomain),
+		cooccurrences:   make(map[string][]goinvestigate.Cooccurrence),
+		security:        make(map[string]*goinvestigate.SecurityFeatures),
+		tags:            make(map[string][]goinvestigate.DomainTag),
+		ipRRHistory:     make(map[rrKey]*goinvestigate.IPRRHistory),
+		domainRRHistory: make(map[rrKey]*goinvestigate.DomainRRHistory),
+		latestDomains:   make(map[string][]string),
+		whois:           make(map[string]*goinvestigate.WhoisRecord),
+		whoisHistory:    make(map[string][]goinvestigate.WhoisRecord),
+		whoisByEmail:    make(map[string][]goinvestigate.WhoisDomain),
+		whoisByNS:       make(map[string][]goinvestigate.WhoisDomain),
+		pdns:            make(map[pdnsKey][]goinvestigate.PDNSRecord),
+		pdnsTimeline:    make(map[string][]goinvestigate.PDNSTimelineEntry),
+		riskScores:      make(map[string]*goinvestigate.DomainRiskScore),
+		subdomains:      make(map[string][]goinvestigate.Subdomain),
+		asForIP:         make(map[string][]goinvestigate.ASInfo),
+		asnPrefixes:     make(map[int][]goinvestigate.ASPrefix),
+		volumes:         make(map[string]*goinvestigate.DomainVolume),
+		errs:            make(map[string]error),
+	}
+}
+
+// Make every query for the given domain or IP fail with err.
+func (f *Investigator) AddError(item string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs[itemKey(item)] = err
+}
+
+// Seed the categorization of a domain. The fake returns it as given,
+// whether or not labels are asked for.
+func (f *Investigator) AddCategorization(domain string, cat goinvestigate.DomainCategorization) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.categorizations[domainKey(domain)] = cat
+}
+
+// Seed the related domains of a domain.
+func (f *Investigator) AddRelatedDomains(domain string, related []goinvestigate.RelatedDomain) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.related[domainKey(domain)] = related
+}
+
+// Seed the cooccurrences of a domain.
+func (f *Investigator) AddCooccurrences(domain string, cooccurrences []goinvestigate.Cooccurrence) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cooccurrences[domainKey(domain)] = cooccurrences
+}
+
+// Seed the Security Information of a domain.
+func (f *Investigator) AddSecurity(domain string, security *goinvestigate.SecurityFeatures) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.security[domainKey(domain)] = security
+}
+
+// Seed the domain tagging dates of a domain.
+func (f *Investigator) AddDomainTags(domain string, tags []goinvestigate.DomainTag) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tags[domainKey(domain)] = tags
+}
+
+// Seed the RR History of an IP for the given query type.
+func (f *Investigator) AddIpRRHistory(ip string, queryType goinvestigate.QueryType, history *goinvestigate.IPRRHistory) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ipRRHistory[rrKey{ipKey(ip), queryType}] = history
+}
+
+// Seed the RR History of a domain for the given query type.
+func (f *Investigator) AddDomainRRHistory(domain string, queryType goinvestigate.QueryType, history *goinvestigate.DomainRRHistory) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.domainRRHistory[rrKey{domainKey(domain), queryType}] = history
+}
+
+// Seed the latest known malicious domains of an IP.
+func (f *Investigator) AddLatestDomains(ip string, domains []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latestDomains[ipKey(ip)] = domains
+}
+
+// Seed the labels of the categories, by ID. Until this is called, the fake
+// returns the snapshot bundled with goinvestigate.
+func (f *Investigator) SetCategories(labels map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.categories = labels
+}
+
+// Seed the current WHOIS record of a domain.
+func (f *Investigator) AddWhois(domain string, record *goinvestigate.WhoisRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.whois[domainKey(domain)] = record
+}
+
+// Seed the historical WHOIS records of a domain, newest first. The fake
+// pages through them as the API would.
+func (f *Investigator) AddWhoisHistory(domain string, records []goinvestigate.WhoisRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.whoisHistory[domainKey(domain)] = records
+}
+
+// Seed the domains whose WHOIS records have an email address.
+func (f *Investigator) AddWhoisByEmail(email string, domains []goinvestigate.WhoisDomain) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.whoisByEmail[emailKey(email)] = domains
+}
+
+// Seed the domains whose WHOIS records have a name server.
+func (f *Investigator) AddWhoisByNameserver(nameserver string, domains []goinvestigate.WhoisDomain) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.whoisByNS[domainKey(nameserver)] = domains
+}
+
+// Seed the passive DNS records of a domain and its subdomains. The fake
+// applies the RecordTypes and paging of the options it's queried with;
+// other filters are ignored.
+func (f *Investigator) AddPDNSDomain(domain string, records []goinvestigate.PDNSRecord) {
+	f.addPDNS("domain", domainKey(domain), records)
+}
+
+// Seed the passive DNS records of exactly a name.
+func (f *Investigator) AddPDNSName(name string, records []goinvestigate.PDNSRecord) {
+	f.addPDNS("name", domainKey(name), records)
+}
+
+// Seed the passive DNS records pointing to an IP.
+func (f *Investigator) AddPDNSIP(ip string, records []goinvestigate.PDNSRecord) {
+	f.addPDNS("ip", ipKey(ip), records)
+}
+
+// Seed the passive DNS records matching a raw query.
+func (f *Investigator) AddPDNSRaw(query string, records []goinvestigate.PDNSRecord) {
+	f.addPDNS("raw", query, records)
+}
+
+func (f *Investigator) addPDNS(endpoint string, item string, records []goinvestigate.PDNSRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pdns[pdnsKey{endpoint, item}] = records
+}
+
+// Seed the classification timeline of a domain.
+func (f *Investigator) AddPDNSTimeline(domain string, timeline []goinvestigate.PDNSTimelineEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pdnsTimeline[domainKey(domain)] = timeline
+}
+
+// Seed the risk score of a domain.
+func (f *Investigator) AddRiskScore(domain string, score *goinvestigate.DomainRiskScore) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.riskScores[domainKey(domain)] = score
+}
+
+// Seed the subdomains of a domain. The fake sorts them by name, and pages
+// through them as the API would.
+func (f *Investigator) AddSubdomains(domain string, subdomains []goinvestigate.Subdomain) {
+	sorted := slices.Clone(subdomains)
+	slices.SortFunc(sorted, func(a, b goinvestigate.Subdomain) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subdomains[domainKey(domain)] = sorted
+}
+
+// Seed the autonomous systems which announce routes to an IP.
+func (f *Investigator) AddASForIP(ip string, systems []goinvestigate.ASInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.asForIP[ipKey(ip)] = systems
+}
+
+// Seed the prefixes announced by an autonomous system.
+func (f *Investigator) AddPrefixesForASN(asn int, prefixes []goinvestigate.ASPrefix) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.asnPrefixes[asn] = prefixes
+}
+
+// Seed the query volume of a domain. The fake gives the points between the
+// start and stop it's asked for, whatever the match.
+func (f *Investigator) AddDomainVolume(domain string, volume *goinvestigate.DomainVolume) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.volumes[domainKey(domain)] = volume
+}
+
+// The items of the given page, and whether there are more after it.
+func pageOf[T any](items []T, page goinvestigate.Page) ([]T, bool) {
+	start := min(page.Offset, len(items))
+	end := len(items)
+	if page.Limit > 0 {
+		end = min(start+page.Limit, end)
+	}
+	return items[start:end], end < len(items)
+}
+
+// The key a domain is seeded under: its normalized form, as the client
+// sends it. Domains which can't be normalized are kept as given, though
+// queries for them fail before they're looked up.
+func domainKey(domain string) string {
+	if normalized, err := goinvestigate.NormalizeDomain(domain); err == nil {
+		return normalized
+	}
+	return domain
+}
+
+// Like domainKey, but for IPs.
+func ipKey(ip string) string {
+	if normalized, err := goinvestigate.NormalizeIP(ip); err == nil {
+		return normalized
+	}
+	return ip
+}
+
+// Like domainKey, but for email addresses, which the client lowercases.
+func emailKey(email string) string {
+	if normalized, err := goinvestigate.NormalizeEmail(email); err == nil {
+		return normalized
+	}
+	return email
+}
+
+// Whether the client accepts the query type.
+func queryTypeSupported(qType goinvestigate.QueryType) bool {
+	return slices.Contains(goinvestigate.SupportedQueryTypes(), qType)
+}
+
+// The key of an item given to AddError, which may be an IP or a domain.
+func itemKey(item string) string {
+	if normalized, err := goinvestigate.NormalizeIP(item); err == nil {
+		return normalized
+	}
+	return domainKey(item)
+}
+
+// Looks up the seeded value for item in m.
+func lookup[K comparable, V any](ctx context.Context, f *Investigator, m map[K]V, key K, item string, endpoint string) (V, error) {
+	var zero V
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if err, ok := f.errs[item]; ok {
+		return zero, err
+	}
+
+	v, ok := m[key]
+	if !ok {
+		return zero, &goinvestigate.APIError{
+			StatusCode: http.StatusNotFound,
+			Method:     "GET",
+			Endpoint:   endpoint,
+		}
+	}
+	return v, nil
+}
+
+func (f *Investigator) Categorization(domain string, labels bool) (*goinvestigate.DomainCategorization, error) {
+	return f.CategorizationContext(context.Background(), domain, labels)
+}
+
+func (f *Investigator) CategorizationContext(ctx context.Context, domain string, labels bool) (*goinvestigate.DomainCategorization, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	cat, err := lookup(ctx, f, f.categorizations, domain, domain, "/domains/categorization/"+domain)
+	if err != nil {
+		return nil, err
+	}
+	return &cat, nil
+}
+
+func (f *Investigator) Categorizations(domains []string, labels bool) (map[string]goinvestigate.DomainCategorization, error) {
+	return f.CategorizationsContext(context.Background(), domains, labels)
+}
+
+// Like the real client, domains which weren't seeded are left out of the
+// result rather than causing an error, and the result is keyed by the
+// normalized names. Invalid domains, and those seeded with an error, are
+// each listed in a *goinvestigate.ChunkError returned along with the rest.
+func (f *Investigator) CategorizationsContext(ctx context.Context, domains []string, labels bool) (map[string]goinvestigate.DomainCategorization, error) {
+	resp := make(map[string]goinvestigate.DomainCategorization)
+	var failures []goinvestigate.ChunkFailure
+	seen := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		if seen[domainKey(domain)] {
+			continue
+		}
+		seen[domainKey(domain)] = true
+
+		cat, err := f.CategorizationContext(ctx, domain, labels)
+		switch {
+		case err == nil:
+			resp[domainKey(domain)] = *cat
+		case !errors.Is(err, goinvestigate.ErrNotFound):
+			failures = append(failures, goinvestigate.ChunkFailure{Domains: []string{domain}, Err: err})
+		}
+	}
+
+	if len(failures) > 0 {
+		return resp, &goinvestigate.ChunkError{Failures: failures}
+	}
+	return resp, nil
+}
+
+func (f *Investigator) RelatedDomains(domain string) ([]goinvestigate.RelatedDomain, error) {
+	return f.RelatedDomainsContext(context.Background(), domain)
+}
+
+func (f *Investigator) RelatedDomainsContext(ctx context.Context, domain string) ([]goinvestigate.RelatedDomain, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.related, domain, domain, "/links/name/"+domain+".json")
+}
+
+func (f *Investigator) Cooccurrences(domain string) ([]goinvestigate.Cooccurrence, error) {
+	return f.CooccurrencesContext(context.Background(), domain)
+}
+
+func (f *Investigator) CooccurrencesContext(ctx context.Context, domain string) ([]goinvestigate.Cooccurrence, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.cooccurrences, domain, domain, "/recommendations/name/"+domain+".json")
+}
+
+func (f *Investigator) RelatedDomainsResult(domain string) (*goinvestigate.RelatedDomainsResult, error) {
+	return f.RelatedDomainsResultContext(context.Background(), domain)
+}
+
+func (f *Investigator) RelatedDomainsResultContext(ctx context.Context, domain string) (*goinvestigate.RelatedDomainsResult, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	related, err := f.RelatedDomainsContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &goinvestigate.RelatedDomainsResult{Found: true, RelatedDomains: related}, nil
+}
+
+func (f *Investigator) CooccurrencesResult(domain string) (*goinvestigate.CooccurrencesResult, error) {
+	return f.CooccurrencesResultContext(context.Background(), domain)
+}
+
+func (f *Investigator) CooccurrencesResultContext(ctx context.Context, domain string) (*goinvestigate.CooccurrencesResult, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	cooccurrences, err := f.CooccurrencesContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &goinvestigate.CooccurrencesResult{Found: true, Cooccurrences: cooccurrences}, nil
+}
+
+func (f *Investigator) Security(domain string) (*goinvestigate.SecurityFeatures, error) {
+	return f.SecurityContext(context.Background(), domain)
+}
+
+func (f *Investigator) SecurityContext(ctx context.Context, domain string) (*goinvestigate.SecurityFeatures, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.security, domain, domain, "/security/name/"+domain+".json")
+}
+
+func (f *Investigator) DomainTags(domain string) ([]goinvestigate.DomainTag, error) {
+	return f.DomainTagsContext(context.Background(), domain)
+}
+
+func (f *Investigator) DomainTagsContext(ctx context.Context, domain string) ([]goinvestigate.DomainTag, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.tags, domain, domain, "/domains/"+domain+"/latest_tags")
+}
+
+func (f *Investigator) IpRRHistory(ip string, queryType goinvestigate.QueryType) (*goinvestigate.IPRRHistory, error) {
+	return f.IpRRHistoryContext(context.Background(), ip, queryType)
+}
+
+func (f *Investigator) IpRRHistoryContext(ctx context.Context, ip string, queryType goinvestigate.QueryType) (*goinvestigate.IPRRHistory, error) {
+	if !queryTypeSupported(queryType) {
+		return nil, goinvestigate.ErrUnsupportedQueryType
+	}
+	ip, err := goinvestigate.NormalizeIP(ip)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.ipRRHistory, rrKey{ip, queryType}, ip, "/dnsdb/ip/"+string(queryType)+"/"+ip+".json")
+}
+
+func (f *Investigator) DomainRRHistory(domain string, queryType goinvestigate.QueryType) (*goinvestigate.DomainRRHistory, error) {
+	return f.DomainRRHistoryContext(context.Background(), domain, queryType)
+}
+
+func (f *Investigator) DomainRRHistoryContext(ctx context.Context, domain string, queryType goinvestigate.QueryType) (*goinvestigate.DomainRRHistory, error) {
+	if !queryTypeSupported(queryType) {
+		return nil, goinvestigate.ErrUnsupportedQueryType
+	}
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.domainRRHistory, rrKey{domain, queryType}, domain, "/dnsdb/name/"+string(queryType)+"/"+domain+".json")
+}
+
+func (f *Investigator) LatestDomains(ip string) ([]string, error) {
+	return f.LatestDomainsContext(context.Background(), ip)
+}
+
+func (f *Investigator) LatestDomainsContext(ctx context.Context, ip string) ([]string, error) {
+	ip, err := goinvestigate.NormalizeIP(ip)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.latestDomains, ip, ip, "/ips/"+ip+"/latest_domains")
+}
+
+func (f *Investigator) Categories() (map[string]string, error) {
+	return f.CategoriesContext(context.Background())
+}
+
+func (f *Investigator) CategoriesContext(ctx context.Context) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.categories == nil {
+		return goinvestigate.BundledCategories(), nil
+	}
+	return f.categories, nil
+}
+
+func (f *Investigator) Whois(domain string) (*goinvestigate.WhoisRecord, error) {
+	return f.WhoisContext(context.Background(), domain)
+}
+
+func (f *Investigator) WhoisContext(ctx context.Context, domain string) (*goinvestigate.WhoisRecord, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.whois, domain, domain, "/whois/"+domain)
+}
+
+func (f *Investigator) WhoisHistory(domain string, page goinvestigate.Page) ([]goinvestigate.WhoisRecord, error) {
+	return f.WhoisHistoryContext(context.Background(), domain, page)
+}
+
+func (f *Investigator) WhoisHistoryContext(ctx context.Context, domain string, page goinvestigate.Page) ([]goinvestigate.WhoisRecord, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	records, err := lookup(ctx, f, f.whoisHistory, domain, domain, "/whois/"+domain+"/history")
+	if err != nil {
+		return nil, err
+	}
+	records, _ = pageOf(records, page)
+	return records, nil
+}
+
+func (f *Investigator) WhoisByEmail(email string, page goinvestigate.Page) (*goinvestigate.WhoisDomains, error) {
+	return f.WhoisByEmailContext(context.Background(), email, page)
+}
+
+func (f *Investigator) WhoisByEmailContext(ctx context.Context, email string, page goinvestigate.Page) (*goinvestigate.WhoisDomains, error) {
+	email, err := goinvestigate.NormalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	domains, err := lookup(ctx, f, f.whoisByEmail, email, email, "/whois/emails/"+email)
+	if err != nil {
+		return nil, err
+	}
+	return whoisDomains(domains, page), nil
+}
+
+func (f *Investigator) WhoisByNameserver(nameserver string, page goinvestigate.Page) (*goinvestigate.WhoisDomains, error) {
+	return f.WhoisByNameserverContext(context.Background(), nameserver, page)
+}
+
+func (f *Investigator) WhoisByNameserverContext(ctx context.Context, nameserver string, page goinvestigate.Page) (*goinvestigate.WhoisDomains, error) {
+	nameserver, err := goinvestigate.NormalizeDomain(nameserver)
+	if err != nil {
+		return nil, err
+	}
+	domains, err := lookup(ctx, f, f.whoisByNS, nameserver, nameserver, "/whois/nameservers/"+nameserver)
+	if err != nil {
+		return nil, err
+	}
+	return whoisDomains(domains, page), nil
+}
+
+func whoisDomains(domains []goinvestigate.WhoisDomain, page goinvestigate.Page) *goinvestigate.WhoisDomains {
+	pageDomains, more := pageOf(domains, page)
+	return &goinvestigate.WhoisDomains{
+		TotalResults:      len(domains),
+		MoreDataAvailable: more,
+		Limit:             page.Limit,
+		Domains:           pageDomains,
+	}
+}
+
+func (f *Investigator) PDNSDomain(domain string, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+	return f.PDNSDomainContext(context.Background(), domain, opts)
+}
+
+func (f *Investigator) PDNSDomainContext(ctx context.Context, domain string, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return f.pdnsResult(ctx, "domain", domain, opts)
+}
+
+func (f *Investigator) PDNSName(name string, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+	return f.PDNSNameContext(context.Background(), name, opts)
+}
+
+func (f *Investigator) PDNSNameContext(ctx context.Context, name string, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+	name, err := goinvestigate.NormalizeDomain(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.pdnsResult(ctx, "name", name, opts)
+}
+
+func (f *Investigator) PDNSIP(ip string, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+	return f.PDNSIPContext(context.Background(), ip, opts)
+}
+
+func (f *Investigator) PDNSIPContext(ctx context.Context, ip string, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+	ip, err := goinvestigate.NormalizeIP(ip)
+	if err != nil {
+		return nil, err
+	}
+	return f.pdnsResult(ctx, "ip", ip, opts)
+}
+
+func (f *Investigator) PDNSRaw(query string, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+	return f.PDNSRawContext(context.Background(), query, opts)
+}
+
+func (f *Investigator) PDNSRawContext(ctx context.Context, query string, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, &goinvestigate.ValidationError{Kind: "query", Input: query, Reason: "empty"}
+	}
+	return f.pdnsResult(ctx, "raw", query, opts)
+}
+
+func (f *Investigator) pdnsResult(ctx context.Context, endpoint string, item string, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+	for _, qType := range opts.RecordTypes {
+		if !queryTypeSupported(qType) {
+			return nil, fmt.Errorf("%w: %s", goinvestigate.ErrUnsupportedQueryType, qType)
+		}
+	}
+
+	records, err := lookup(ctx, f, f.pdns, pdnsKey{endpoint, item}, item, "/pdns/"+endpoint+"/"+item)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.RecordTypes) > 0 {
+		var filtered []goinvestigate.PDNSRecord
+		for _, record := range records {
+			if slices.Contains(opts.RecordTypes, record.Type) {
+				filtered = append(filtered, record)
+			}
+		}
+		records = filtered
+	}
+
+	page, more := pageOf(records, opts.Page)
+	return &goinvestigate.PDNSResult{
+		PageInfo: goinvestigate.PDNSPageInfo{
+			HasMoreRecords:  more,
+			Offset:          opts.Offset,
+			Limit:           opts.Limit,
+			TotalNumRecords: len(records),
+		},
+		Records: page,
+	}, nil
+}
+
+func (f *Investigator) PDNSTimeline(domain string) ([]goinvestigate.PDNSTimelineEntry, error) {
+	return f.PDNSTimelineContext(context.Background(), domain)
+}
+
+func (f *Investigator) PDNSTimelineContext(ctx context.Context, domain string) ([]goinvestigate.PDNSTimelineEntry, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.pdnsTimeline, domain, domain, "/pdns/timeline/"+domain)
+}
+
+func (f *Investigator) RiskScore(domain string) (*goinvestigate.DomainRiskScore, error) {
+	return f.RiskScoreContext(context.Background(), domain)
+}
+
+func (f *Investigator) RiskScoreContext(ctx context.Context, domain string) (*goinvestigate.DomainRiskScore, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.riskScores, domain, domain, "/domains/risk-score/"+domain)
+}
+
+func (f *Investigator) SubdomainsPage(domain string, after string, limit int) ([]goinvestigate.Subdomain, error) {
+	return f.SubdomainsPageContext(context.Background(), domain, after, limit)
+}
+
+func (f *Investigator) SubdomainsPageContext(ctx context.Context, domain string, after string, limit int) ([]goinvestigate.Subdomain, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	subdomains, err := lookup(ctx, f, f.subdomains, domain, domain, "/subdomains/"+domain)
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := slices.BinarySearchFunc(subdomains, after, func(s goinvestigate.Subdomain, name string) int {
+		return strings.Compare(s.Name, name)
+	})
+	if start < len(subdomains) && after != "" && subdomains[start].Name == after {
+		start++
+	}
+	page, _ := pageOf(subdomains[start:], goinvestigate.Page{Limit: limit})
+	return page, nil
+}
+
+func (f *Investigator) ASForIP(ip string) ([]goinvestigate.ASInfo, error) {
+	return f.ASForIPContext(context.Background(), ip)
+}
+
+func (f *Investigator) ASForIPContext(ctx context.Context, ip string) ([]goinvestigate.ASInfo, error) {
+	ip, err := goinvestigate.NormalizeIP(ip)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ctx, f, f.asForIP, ip, ip, "/bgp_routes/ip/"+ip+"/as_for_ip.json")
+}
+
+func (f *Investigator) PrefixesForASN(asn int) ([]goinvestigate.ASPrefix, error) {
+	return f.PrefixesForASNContext(context.Background(), asn)
+}
+
+func (f *Investigator) PrefixesForASNContext(ctx context.Context, asn int) ([]goinvestigate.ASPrefix, error) {
+	item := strconv.Itoa(asn)
+	if asn <= 0 || int64(asn) > math.MaxUint32 {
+		return nil, &goinvestigate.ValidationError{Kind: "ASN", Input: item, Reason: "out of range"}
+	}
+	return lookup(ctx, f, f.asnPrefixes, asn, item, "/bgp_routes/asn/"+item+"/prefixes_for_asn.json")
+}
+
+func (f *Investigator) DomainVolume(domain string, start, stop time.Time, match goinvestigate.VolumeMatch) (*goinvestigate.DomainVolume, error) {
+	return f.DomainVolumeContext(context.Background(), domain, start, stop, match)
+}
+
+func (f *Investigator) DomainVolumeContext(ctx context.Context, domain string, start, stop time.Time, match goinvestigate.VolumeMatch) (*goinvestigate.DomainVolume, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	switch match {
+	case "", goinvestigate.MatchAll, goinvestigate.MatchExact, goinvestigate.MatchComponent:
+	default:
+		return nil, &goinvestigate.ValidationError{Kind: "match", Input: string(match), Reason: "not all, exact or component"}
+	}
+
+	if !start.IsZero() && !stop.IsZero() && !start.Before(stop) {
+		return nil, &goinvestigate.ValidationError{Kind: "time range", Input: start.String() + " to " + stop.String(), Reason: "start is not before stop"}
+	}
+
+	volume, err := lookup(ctx, f, f.volumes, domain, domain, "/domains/volume/"+domain)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &goinvestigate.DomainVolume{Start: start, Stop: stop}
+	for _, p := range volume.Points {
+		if (start.IsZero() || !p.Time.Before(start)) && (stop.IsZero() || !p.Time.After(stop)) {
+			out.Points = append(out.Points, p)
+		}
+	}
+	if len(out.Points) > 0 {
+		out.Start = out.Points[0].Time
+		out.Stop = out.Points[len(out.Points)-1].Time
+	}
+	return out, nil
+}
+
+func (f *Investigator) SecurityBulk(domains []string) []goinvestigate.Result[*goinvestigate.SecurityFeatures] {
+	return f.SecurityBulkContext(context.Background(), domains)
+}
+
+func (f *Investigator) SecurityBulkContext(ctx context.Context, domains []string) []goinvestigate.Result[*goinvestigate.SecurityFeatures] {
+	return goinvestigate.BulkLookup(ctx, 1, domains, f.SecurityContext)
+}
+
+func (f *Investigator) RelatedDomainsBulk(domains []string) []goinvestigate.Result[[]goinvestigate.RelatedDomain] {
+	return f.RelatedDomainsBulkContext(context.Background(), domains)
+}
+
+func (f *Investigator) RelatedDomainsBulkContext(ctx context.Context, domains []string) []goinvestigate.Result[[]goinvestigate.RelatedDomain] {
+	return goinvestigate.BulkLookup(ctx, 1, domains, f.RelatedDomainsContext)
+}
+
+func (f *Investigator) CooccurrencesBulk(domains []string) []goinvestigate.Result[[]goinvestigate.Cooccurrence] {
+	return f.CooccurrencesBulkContext(context.Background(), domains)
+}
+
+func (f *Investigator) CooccurrencesBulkContext(ctx context.Context, domains []string) []goinvestigate.Result[[]goinvestigate.Cooccurrence] {
+	return goinvestigate.BulkLookup(ctx, 1, domains, f.CooccurrencesContext)
+}
+
+func (f *Investigator) DomainTagsBulk(domains []string) []goinvestigate.Result[[]goinvestigate.DomainTag] {
+	return f.DomainTagsBulkContext(context.Background(), domains)
+}
+
+func (f *Investigator) DomainTagsBulkContext(ctx context.Context, domains []string) []goinvestigate.Result[[]goinvestigate.DomainTag] {
+	return goinvestigate.BulkLookup(ctx, 1, domains, f.DomainTagsContext)
+}
+
+func (f *Investigator) DomainRRHistoryBulk(domains []string, queryType goinvestigate.QueryType) []goinvestigate.Result[*goinvestigate.DomainRRHistory] {
+	return f.DomainRRHistoryBulkContext(context.Background(), domains, queryType)
+}
+
+func (f *Investigator) DomainRRHistoryBulkContext(ctx context.Context, domains []string, queryType goinvestigate.QueryType) []goinvestigate.Result[*goinvestigate.DomainRRHistory] {
+	return goinvestigate.BulkLookup(ctx, 1, domains, func(ctx context.Context, domain string) (*goinvestigate.DomainRRHistory, error) {
+		return f.DomainRRHistoryContext(ctx, domain, queryType)
+	})
+}
+
+func (f *Investigator) IpRRHistoryBulk(ips []string, queryType goinvestigate.QueryType) []goinvestigate.Result[*goinvestigate.IPRRHistory] {
+	return f.IpRRHistoryBulkContext(context.Background(), ips, queryType)
+}
+
+func (f *Investigator) IpRRHistoryBulkContext(ctx context.Context, ips []string, queryType goinvestigate.QueryType) []goinvestigate.Result[*goinvestigate.IPRRHistory] {
+	return goinvestigate.BulkLookup(ctx, 1, ips, func(ctx context.Context, ip string) (*goinvestigate.IPRRHistory, error) {
+		return f.IpRRHistoryContext(ctx, ip, queryType)
+	})
+}
+
+func (f *Investigator) LatestDomainsBulk(ips []string) []goinvestigate.Result[[]string] {
+	return f.LatestDomainsBulkContext(context.Background(), ips)
+}
+
+func (f *Investigator) LatestDomainsBulkContext(ctx context.Context, ips []string) []goinvestigate.Result[[]string] {
+	return goinvestigate.BulkLookup(ctx, 1, ips, f.LatestDomainsContext)
+}
+
+func (f *Investigator) RiskScoreBulk(domains []string) []goinvestigate.Result[*goinvestigate.DomainRiskScore] {
+	return f.RiskScoreBulkContext(context.Background(), domains)
+}
+
+func (f *Investigator) RiskScoreBulkContext(ctx context.Context, domains []string) []goinvestigate.Result[*goinvestigate.DomainRiskScore] {
+	return goinvestigate.BulkLookup(ctx, 1, domains, f.RiskScoreContext)
+}
+
+func (f *Investigator) SecurityStream(ctx context.Context, domains []string, opts ...goinvestigate.StreamOption) iter.Seq2[string, goinvestigate.Result[*goinvestigate.SecurityFeatures]] {
+	return goinvestigate.StreamLookup(ctx, 1, domains, f.SecurityContext, opts...)
+}
+
+func (f *Investigator) RelatedDomainsStream(ctx context.Context, domains []string, opts ...goinvestigate.StreamOption) iter.Seq2[string, goinvestigate.Result[[]goinvestigate.RelatedDomain]] {
+	return goinvestigate.StreamLookup(ctx, 1, domains, f.RelatedDomainsContext, opts...)
+}
+
+func (f *Investigator) CooccurrencesStream(ctx context.Context, domains []string, opts ...goinvestigate.StreamOption) iter.Seq2[string, goinvestigate.Result[[]goinvestigate.Cooccurrence]] {
+	return goinvestigate.StreamLookup(ctx, 1, domains, f.CooccurrencesContext, opts...)
+}
+
+func (f *Investigator) DomainTagsStream(ctx context.Context, domains []string, opts ...goinvestigate.StreamOption) iter.Seq2[string, goinvestigate.Result[[]goinvestigate.DomainTag]] {
+	return goinvestigate.StreamLookup(ctx, 1, domains, f.DomainTagsContext, opts...)
+}
+
+func (f *Investigator) DomainRRHistoryStream(ctx context.Context, domains []string, queryType goinvestigate.QueryType, opts ...goinvestigate.StreamOption) iter.Seq2[string, goinvestigate.Result[*goinvestigate.DomainRRHistory]] {
+	return goinvestigate.StreamLookup(ctx, 1, domains, func(ctx context.Context, domain string) (*goinvestigate.DomainRRHistory, error) {
+		return f.DomainRRHistoryContext(ctx, domain, queryType)
+	}, opts...)
+}
+
+func (f *Investigator) IpRRHistoryStream(ctx context.Context, ips []string, queryType goinvestigate.QueryType, opts ...goinvestigate.StreamOption) iter.Seq2[string, goinvestigate.Result[*goinvestigate.IPRRHistory]] {
+	return goinvestigate.StreamLookup(ctx, 1, ips, func(ctx context.Context, ip string) (*goinvestigate.IPRRHistory, error) {
+		return f.IpRRHistoryContext(ctx, ip, queryType)
+	}, opts...)
+}
+
+func (f *Investigator) LatestDomainsStream(ctx context.Context, ips []string, opts ...goinvestigate.StreamOption) iter.Seq2[string, goinvestigate.Result[[]string]] {
+	return goinvestigate.StreamLookup(ctx, 1, ips, f.LatestDomainsContext, opts...)
+}
+
+func (f *Investigator) RiskScoreStream(ctx context.Context, domains []string, opts ...goinvestigate.StreamOption) iter.Seq2[string, goinvestigate.Result[*goinvestigate.DomainRiskScore]] {
+	return goinvestigate.StreamLookup(ctx, 1, domains, f.RiskScoreContext, opts...)
+}
+
+// Iterates over the items of a paged method of the fake, pageSize at a
+// time, the way the client's iterators do.
+func pages[T any](ctx context.Context, pageSize int, fetch func(ctx context.Context, page goinvestigate.Page) ([]T, bool, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		page := goinvestigate.Page{Limit: pageSize}
+		for {
+			items, more, err := fetch(ctx, page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if !more || len(items) == 0 {
+				return
+			}
+			page.Offset += len(items)
+		}
+	}
+}
+
+func pdnsPages(ctx context.Context, opts goinvestigate.PDNSOptions, fetch func(context.Context, goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error)) iter.Seq2[goinvestigate.PDNSRecord, error] {
+	pageSize := opts.Limit
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	start := opts.Offset
+	return pages(ctx, pageSize, func(ctx context.Context, page goinvestigate.Page) ([]goinvestigate.PDNSRecord, bool, error) {
+		opts.Page = goinvestigate.Page{Limit: page.Limit, Offset: start + page.Offset}
+		resp, err := fetch(ctx, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Records, resp.PageInfo.HasMoreRecords, nil
+	})
+}
+
+func (f *Investigator) PDNSDomainAll(ctx context.Context, domain string, opts goinvestigate.PDNSOptions) iter.Seq2[goinvestigate.PDNSRecord, error] {
+	return pdnsPages(ctx, opts, func(ctx context.Context, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+		return f.PDNSDomainContext(ctx, domain, opts)
+	})
+}
+
+func (f *Investigator) PDNSNameAll(ctx context.Context, name string, opts goinvestigate.PDNSOptions) iter.Seq2[goinvestigate.PDNSRecord, error] {
+	return pdnsPages(ctx, opts, func(ctx context.Context, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+		return f.PDNSNameContext(ctx, name, opts)
+	})
+}
+
+func (f *Investigator) PDNSIPAll(ctx context.Context, ip string, opts goinvestigate.PDNSOptions) iter.Seq2[goinvestigate.PDNSRecord, error] {
+	return pdnsPages(ctx, opts, func(ctx context.Context, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+		return f.PDNSIPContext(ctx, ip, opts)
+	})
+}
+
+func (f *Investigator) PDNSRawAll(ctx context.Context, query string, opts goinvestigate.PDNSOptions) iter.Seq2[goinvestigate.PDNSRecord, error] {
+	return pdnsPages(ctx, opts, func(ctx context.Context, opts goinvestigate.PDNSOptions) (*goinvestigate.PDNSResult, error) {
+		return f.PDNSRawContext(ctx, query, opts)
+	})
+}
+
+func (f *Investigator) WhoisHistoryAll(ctx context.Context, domain string) iter.Seq2[goinvestigate.WhoisRecord, error] {
+	return pages(ctx, 100, func(ctx context.Context, page goinvestigate.Page) ([]goinvestigate.WhoisRecord, bool, error) {
+		records, err := f.WhoisHistoryContext(ctx, domain, page)
+		return records, len(records) == page.Limit, err
+	})
+}
+
+func (f *Investigator) WhoisByEmailAll(ctx context.Context, email string) iter.Seq2[goinvestigate.WhoisDomain, error] {
+	return pages(ctx, 100, func(ctx context.Context, page goinvestigate.Page) ([]goinvestigate.WhoisDomain, bool, error) {
+		resp, err := f.WhoisByEmailContext(ctx, email, page)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Domains, resp.MoreDataAvailable, nil
+	})
+}
+
+func (f *Investigator) WhoisByNameserverAll(ctx context.Context, nameserver string) iter.Seq2[goinvestigate.WhoisDomain, error] {
+	return pages(ctx, 100, func(ctx context.Context, page goinvestigate.Page) ([]goinvestigate.WhoisDomain, bool, error) {
+		resp, err := f.WhoisByNameserverContext(ctx, nameserver, page)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Domains, resp.MoreDataAvailable, nil
+	})
+}
+
+func (f *Investigator) Subdomains(domain string) iter.Seq2[goinvestigate.Subdomain, error] {
+	return f.SubdomainsContext(context.Background(), domain)
+}
+
+func (f *Investigator) SubdomainsContext(ctx context.Context, domain string) iter.Seq2[goinvestigate.Subdomain, error] {
+	return func(yield func(goinvestigate.Subdomain, error) bool) {
+		after := ""
+		for {
+			page, err := f.SubdomainsPageContext(ctx, domain, after, 100)
+			if err != nil {
+				yield(goinvestigate.Subdomain{}, err)
+				return
+			}
+			if len(page) == 0 {
+				return
+			}
+			for _, sub := range page {
+				if !yield(sub, nil) {
+					return
+				}
+			}
+			after = page[len(page)-1].Name
+		}
+	}
+}
+
+func (f *Investigator) DomainRRHistoryAllTypes(domain string) (*goinvestigate.DomainRRHistory, error) {
+	return f.DomainRRHistoryAllTypesContext(context.Background(), domain)
+}
+
+// Merges the seeded histories of every query type, as the client does.
+func (f *Investigator) DomainRRHistoryAllTypesContext(ctx context.Context, domain string) (*goinvestigate.DomainRRHistory, error) {
+	domain, err := goinvestigate.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	types := goinvestigate.SupportedQueryTypes()
+	histories := make([]*goinvestigate.DomainRRHistory, len(types))
+	errs := make([]error, len(types))
+	for i, qType := range types {
+		histories[i], errs[i] = f.DomainRRHistoryContext(ctx, domain, qType)
+	}
+	return goinvestigate.MergeRRHistories(types, histories, errs)
+}
@@ -0,0 +1,366 @@
+package goinvestigatefake
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/dead10ck/goinvestigate"
+)
+
+// a stand-in for code which depends on an Investigator
+func maliciousDomains(inv goinvestigate.Investigator, domains []string) ([]string, error) {
+	var malicious []string
+	for _, domain := range domains {
+		cat, err := inv.Categorization(domain, true)
+		if errors.Is(err, goinvestigate.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if cat.Status == -1 {
+			malicious = append(malicious, domain)
+		}
+	}
+	return malicious, nil
+}
+
+func TestFakeInvestigator(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddCategorization("bibikun.ru", goinvestigate.DomainCategorization{
+		Status:             -1,
+		SecurityCategories: []string{"Malware"},
+	})
+	inv.AddCategorization("www.amazon.com", goinvestigate.DomainCategorization{Status: 1})
+
+	out, err := maliciousDomains(inv, []string{"www.amazon.com", "unknown.com", "bibikun.ru"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 || out[0] != "bibikun.ru" {
+		t.Fatalf("%v should be [bibikun.ru]", out)
+	}
+
+	inv.AddError("www.amazon.com", goinvestigate.ErrRateLimited)
+	if _, err := maliciousDomains(inv, []string{"www.amazon.com"}); !errors.Is(err, goinvestigate.ErrRateLimited) {
+		t.Fatalf("%v should be %v", err, goinvestigate.ErrRateLimited)
+	}
+}
+
+func TestFakeRRHistory(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	history := &goinvestigate.DomainRRHistory{
+		RRPeriods: []goinvestigate.ResourceRecordPeriod{{FirstSeen: goinvestigate.Timestamp{Raw: "2013-07-31"}}},
+	}
+	inv.AddDomainRRHistory("example.com", "A", history)
+
+	out, err := inv.DomainRRHistory("example.com", "A")
+	if err != nil || out != history {
+		t.Fatalf("got %v, %v; should get the seeded history", out, err)
+	}
+
+	if _, err := inv.DomainRRHistory("example.com", "MX"); !errors.Is(err, goinvestigate.ErrNotFound) {
+		t.Fatalf("%v should be %v", err, goinvestigate.ErrNotFound)
+	}
+
+	if _, err := inv.DomainRRHistory("example.com", "BOGUS"); !errors.Is(err, goinvestigate.ErrUnsupportedQueryType) {
+		t.Fatalf("%v should be %v", err, goinvestigate.ErrUnsupportedQueryType)
+	}
+
+	if _, err := inv.IpRRHistory("93.184.216.34", "BOGUS"); !errors.Is(err, goinvestigate.ErrUnsupportedQueryType) {
+		t.Fatalf("%v should be %v", err, goinvestigate.ErrUnsupportedQueryType)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := inv.DomainRRHistoryContext(ctx, "example.com", "A"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("%v should be %v", err, context.Canceled)
+	}
+}
+
+func TestFakeCategorizations(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddCategorization("a.com", goinvestigate.DomainCategorization{Status: 1})
+
+	out, err := inv.Categorizations([]string{"a.com", "b.com"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 || out["a.com"].Status != 1 {
+		t.Fatalf("unexpected categorizations %v", out)
+	}
+}
+
+func TestFakeCategorizationsFailures(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddCategorization("a.com", goinvestigate.DomainCategorization{Status: 1})
+	inv.AddCategorization("b.com", goinvestigate.DomainCategorization{Status: -1})
+	inv.AddError("b.com", goinvestigate.ErrRateLimited)
+
+	out, err := inv.Categorizations([]string{"A.com.", "b.com", "bad domain"}, false)
+
+	var chunkErr *goinvestigate.ChunkError
+	if !errors.As(err, &chunkErr) || len(chunkErr.Failures) != 2 {
+		t.Fatalf("%v should be a *ChunkError with two failures", err)
+	}
+
+	if !errors.Is(err, goinvestigate.ErrRateLimited) || !errors.Is(err, goinvestigate.ErrInvalidInput) {
+		t.Fatalf("%v should list the seeded error and the invalid domain", err)
+	}
+
+	if len(out) != 1 || out["a.com"].Status != 1 {
+		t.Fatalf("the partial results should be returned, got %v", out)
+	}
+}
+
+func TestFakeNormalizes(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddSecurity("www.test.com", &goinvestigate.SecurityFeatures{DGAScore: -3.5})
+	inv.AddLatestDomains("2001:DB8::1", []string{"bad.example.com"})
+
+	if out, err := inv.Security("WWW.Test.com."); err != nil || out.DGAScore != -3.5 {
+		t.Fatalf("got %+v, %v", out, err)
+	}
+
+	if out, err := inv.LatestDomains("2001:db8:0::1"); err != nil || len(out) != 1 {
+		t.Fatalf("got %v, %v", out, err)
+	}
+
+	var valErr *goinvestigate.ValidationError
+	if _, err := inv.Security("not a domain"); !errors.As(err, &valErr) {
+		t.Fatalf("%v should be a *ValidationError", err)
+	}
+}
+
+func TestFakeValidates(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	start := time.Date(2017, 12, 14, 0, 0, 0, 0, time.UTC)
+	calls := map[string]func() error{
+		"WhoisByEmail": func() error {
+			_, err := inv.WhoisByEmail("not an email", goinvestigate.Page{})
+			return err
+		},
+		"PrefixesForASN": func() error {
+			_, err := inv.PrefixesForASN(0)
+			return err
+		},
+		"DomainVolume match": func() error {
+			_, err := inv.DomainVolume("example.com", time.Time{}, time.Time{}, "bogus")
+			return err
+		},
+		"DomainVolume range": func() error {
+			_, err := inv.DomainVolume("example.com", start, start, goinvestigate.MatchAll)
+			return err
+		},
+		"PDNSRaw": func() error {
+			_, err := inv.PDNSRaw(" ", goinvestigate.PDNSOptions{})
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		var valErr *goinvestigate.ValidationError
+		if err := call(); !errors.As(err, &valErr) {
+			t.Fatalf("%s: %v should be a *ValidationError", name, err)
+		}
+	}
+}
+
+func TestFakeCategories(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	labels, err := inv.Categories()
+	if err != nil || labels["67"] != "Malware" {
+		t.Fatalf("got %v, %v; should get the bundled categories", labels, err)
+	}
+
+	inv.SetCategories(map[string]string{"1": "One"})
+	if labels, _ := inv.Categories(); len(labels) != 1 {
+		t.Fatalf("got %v; should get the seeded categories", labels)
+	}
+}
+
+func TestFakeWhoisPaging(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddWhoisByEmail("admin@example.com", []goinvestigate.WhoisDomain{
+		{Domain: "a.com"}, {Domain: "b.com"}, {Domain: "c.com"},
+	})
+
+	page, err := inv.WhoisByEmail("admin@example.com", goinvestigate.Page{Limit: 2})
+	if err != nil || len(page.Domains) != 2 || !page.MoreDataAvailable {
+		t.Fatalf("got %+v, %v", page, err)
+	}
+
+	page, err = inv.WhoisByEmail("admin@example.com", goinvestigate.Page{Limit: 2, Offset: 2})
+	if err != nil || len(page.Domains) != 1 || page.MoreDataAvailable {
+		t.Fatalf("got %+v, %v", page, err)
+	}
+}
+
+func TestFakePDNS(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddPDNSName("example.com", []goinvestigate.PDNSRecord{
+		{Name: "example.com.", Type: goinvestigate.QueryA, RR: "93.184.216.34"},
+		{Name: "example.com.", Type: goinvestigate.QueryMX, RR: "10 mail.example.com."},
+		{Name: "example.com.", Type: goinvestigate.QueryA, RR: "93.184.216.119"},
+	})
+
+	out, err := inv.PDNSName("example.com", goinvestigate.PDNSOptions{
+		Page:        goinvestigate.Page{Limit: 1},
+		RecordTypes: []goinvestigate.QueryType{goinvestigate.QueryA},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.Records) != 1 || out.PageInfo.TotalNumRecords != 2 || !out.PageInfo.HasMoreRecords {
+		t.Fatalf("unexpected result %+v", out)
+	}
+
+	if _, err := inv.PDNSDomain("example.com", goinvestigate.PDNSOptions{}); !errors.Is(err, goinvestigate.ErrNotFound) {
+		t.Fatalf("%v should be %v", err, goinvestigate.ErrNotFound)
+	}
+
+	_, err = inv.PDNSName("example.com", goinvestigate.PDNSOptions{RecordTypes: []goinvestigate.QueryType{"BOGUS"}})
+	if !errors.Is(err, goinvestigate.ErrUnsupportedQueryType) {
+		t.Fatalf("%v should be %v", err, goinvestigate.ErrUnsupportedQueryType)
+	}
+}
+
+func TestFakeSubdomains(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddSubdomains("example.com", []goinvestigate.Subdomain{
+		{Name: "www.example.com"}, {Name: "api.example.com"}, {Name: "mail.example.com"},
+	})
+
+	page, err := inv.SubdomainsPage("example.com", "", 2)
+	if err != nil || len(page) != 2 || page[0].Name != "api.example.com" {
+		t.Fatalf("got %+v, %v", page, err)
+	}
+
+	page, err = inv.SubdomainsPage("example.com", page[1].Name, 2)
+	if err != nil || len(page) != 1 || page[0].Name != "www.example.com" {
+		t.Fatalf("got %+v, %v", page, err)
+	}
+}
+
+func TestFakeBGP(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddPrefixesForASN(36692, []goinvestigate.ASPrefix{
+		{CIDR: netip.MustParsePrefix("208.67.216.0/21")},
+	})
+
+	prefixes, err := inv.PrefixesForASN(36692)
+	if err != nil || len(prefixes) != 1 {
+		t.Fatalf("got %+v, %v", prefixes, err)
+	}
+
+	if _, err := inv.ASForIP("208.67.222.222"); !errors.Is(err, goinvestigate.ErrNotFound) {
+		t.Fatalf("%v should be %v", err, goinvestigate.ErrNotFound)
+	}
+}
+
+func TestFakeDomainVolume(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	start := time.Date(2017, 12, 14, 0, 0, 0, 0, time.UTC)
+	volume := &goinvestigate.DomainVolume{Start: start, Stop: start.Add(3 * time.Hour)}
+	for i := range 4 {
+		volume.Points = append(volume.Points, goinvestigate.VolumePoint{Time: start.Add(time.Duration(i) * time.Hour), Count: i})
+	}
+	inv.AddDomainVolume("example.com", volume)
+
+	out, err := inv.DomainVolume("example.com", start.Add(time.Hour), time.Time{}, goinvestigate.MatchAll)
+	if err != nil || len(out.Points) != 3 || out.Points[0].Count != 1 || !out.Stop.Equal(volume.Stop) {
+		t.Fatalf("got %+v, %v", out, err)
+	}
+}
+
+func TestFakeBulkAndStreams(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddSecurity("bibikun.ru", &goinvestigate.SecurityFeatures{DGAScore: -3.5})
+
+	// the fake can stand in for code which does lookups in bulk
+	var investigator goinvestigate.Investigator = inv
+	results := investigator.SecurityBulk([]string{"bibikun.ru", "unknown.com"})
+	if len(results) != 2 || results[0].Value.DGAScore != -3.5 || !errors.Is(results[1].Err, goinvestigate.ErrNotFound) {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	var progress goinvestigate.Progress
+	count := 0
+	for item, result := range investigator.SecurityStream(context.Background(), []string{"bibikun.ru", "unknown.com"},
+		goinvestigate.StreamProgress(func(p goinvestigate.Progress) { progress = p })) {
+		if item != result.Item {
+			t.Fatalf("%s yielded with the result for %s", item, result.Item)
+		}
+		count++
+	}
+
+	if count != 2 || progress.Completed != 1 || progress.Failed != 1 {
+		t.Fatalf("got %d results, progress %+v", count, progress)
+	}
+}
+
+func TestFakeIterators(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddSubdomains("example.com", []goinvestigate.Subdomain{{Name: "a.example.com"}, {Name: "b.example.com"}})
+	inv.AddPDNSName("example.com", []goinvestigate.PDNSRecord{{RR: "1.2.3.4"}, {RR: "1.2.3.5"}, {RR: "1.2.3.6"}})
+
+	subdomains := 0
+	for _, err := range inv.Subdomains("example.com") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		subdomains++
+	}
+
+	records := 0
+	for _, err := range inv.PDNSNameAll(context.Background(), "example.com", goinvestigate.PDNSOptions{Page: goinvestigate.Page{Limit: 2}}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		records++
+	}
+
+	if subdomains != 2 || records != 3 {
+		t.Fatalf("got %d subdomains and %d records", subdomains, records)
+	}
+}
+
+func TestFakeDomainRRHistoryAllTypes(t *testing.T) {
+	t.Parallel()
+	inv := New()
+	inv.AddDomainRRHistory("example.com", goinvestigate.QueryMX, &goinvestigate.DomainRRHistory{
+		RRPeriods: []goinvestigate.ResourceRecordPeriod{{FirstSeen: goinvestigate.Timestamp{Time: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)}}},
+	})
+	inv.AddDomainRRHistory("example.com", goinvestigate.QueryA, &goinvestigate.DomainRRHistory{
+		RRPeriods: []goinvestigate.ResourceRecordPeriod{{FirstSeen: goinvestigate.Timestamp{Time: time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)}}},
+	})
+
+	out, err := inv.DomainRRHistoryAllTypes("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.RRPeriods) != 2 || out.RRPeriods[0].FirstSeen.Time.Year() != 2013 {
+		t.Fatalf("unexpected history %+v", out)
+	}
+}
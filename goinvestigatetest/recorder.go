@@ -0,0 +1,201 @@
+package goinvestigatetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Returned by a replaying Recorder for requests it has no fixture for.
+var ErrNoFixture = errors.New("no recorded fixture for request")
+
+// The mode a Recorder works in.
+type Mode int
+
+const (
+	// Serve responses from previously recorded fixtures, without making
+	// any requests.
+	ModeReplay Mode = iota
+	// Make requests with the underlying RoundTripper, saving each exchange
+	// as a fixture.
+	ModeRecord
+)
+
+// A Recorder is an http.RoundTripper which records API exchanges to a
+// fixture directory, and replays them later. Use it to make integration
+// tests deterministic, and runnable without an API key:
+//
+//	rec := goinvestigatetest.NewRecorder("testdata/fixtures", goinvestigatetest.ModeReplay, nil)
+//	inv := goinvestigate.New("any key", goinvestigate.WithTransport(rec))
+//
+// Fixtures are matched on the request's method, path, query and body, but not
+// its host, so exchanges recorded from the live API can be replayed with any
+// base URL. The bearer token is scrubbed from everything which is recorded.
+type Recorder struct {
+	dir  string
+	mode Mode
+	next http.RoundTripper
+}
+
+// Build a Recorder which keeps its fixtures under dir. In ModeRecord,
+// requests are made with next, or http.DefaultTransport if it is nil.
+func NewRecorder(dir string, mode Mode, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{dir, mode, next}
+}
+
+// The recorded form of a request and its response.
+type fixture struct {
+	Request struct {
+		Method string `json:"method"`
+		URI    string `json:"uri"`
+		Body   string `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		Status int         `json:"status"`
+		Header http.Header `json:"header,omitempty"`
+		Body   string      `json:"body"`
+	} `json:"response"`
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// The file the fixture for the given request is kept in. The name starts
+// with the request path, to make the directory easy to browse, and ends with
+// a hash of everything the request is matched on.
+func (r *Recorder) path(method, uri, body string) string {
+	sum := sha256.Sum256([]byte(method + " " + uri + "\n" + body))
+	name := strings.Trim(unsafeChars.ReplaceAllString(uri, "_"), "_")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	return filepath.Join(r.dir, fmt.Sprintf("%s_%s_%s.json", method, name, hex.EncodeToString(sum[:6])))
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	path := r.path(req.Method, req.URL.RequestURI(), string(body))
+
+	if r.mode == ModeReplay {
+		return r.replay(req, path)
+	}
+
+	// the underlying transport still needs the body
+	outReq := req.Clone(req.Context())
+	outReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	resp, err := r.next.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.save(path, req, string(body), resp, respBody); err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, path string) (*http.Response, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoFixture, req.Method, req.URL.RequestURI())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Response.Status, http.StatusText(f.Response.Status)),
+		StatusCode:    f.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Response.Header,
+		Body:          ioutil.NopCloser(strings.NewReader(f.Response.Body)),
+		ContentLength: int64(len(f.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) save(path string, req *http.Request, body string, resp *http.Response, respBody []byte) error {
+	scrub := func(s string) string { return s }
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		if token := strings.TrimPrefix(auth, "Bearer "); token != "" {
+			scrub = strings.NewReplacer(token, "REDACTED").Replace
+		}
+	}
+
+	var f fixture
+	f.Request.Method = req.Method
+	f.Request.URI = scrub(req.URL.RequestURI())
+	f.Request.Body = scrub(body)
+	f.Response.Status = resp.StatusCode
+	f.Response.Body = scrub(string(respBody))
+	f.Response.Header = make(http.Header)
+	for k, vs := range resp.Header {
+		if k == "Set-Cookie" {
+			continue
+		}
+		for _, v := range vs {
+			f.Response.Header.Add(k, scrub(v))
+		}
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that concurrent recordings of the
+	// same request don't interleave
+	tmp, err := ioutil.TempFile(r.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,90 @@
+package goinvestigatetest
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func roundTrip(t *testing.T, rt http.RoundTripper, method, url, key, body string) (*http.Response, string, error) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(respBody), nil
+}
+
+func TestRecordReplay(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	srv := NewServer()
+	srv.SetResponse("/security/name/www.test.com.json", `{"attack": "`+srv.Key+`"}`)
+
+	rec := NewRecorder(dir, ModeRecord, nil)
+	resp, recorded, err := roundTrip(t, rec, "GET", srv.URL+"/security/name/www.test.com.json", srv.Key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+
+	_, recordedPost, err := roundTrip(t, rec, "POST", srv.URL+"/domains/categorization/", srv.Key, `["www.amazon.com"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("recorded %d fixtures, should have recorded 2", len(files))
+	}
+
+	for _, file := range files {
+		data, _ := ioutil.ReadFile(file)
+		if strings.Contains(string(data), srv.Key) {
+			t.Fatalf("%s contains the API key:\n%s", file, data)
+		}
+	}
+
+	// the server is gone, and the host doesn't matter when replaying
+	replay := NewRecorder(dir, ModeReplay, nil)
+	resp, replayed, err := roundTrip(t, replay, "GET", "https://example.invalid/security/name/www.test.com.json", "other key", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected replayed response %+v", resp)
+	}
+
+	if replayed != strings.Replace(recorded, srv.Key, "REDACTED", 1) {
+		t.Fatalf("replayed %s, recorded %s", replayed, recorded)
+	}
+
+	_, replayedPost, err := roundTrip(t, replay, "POST", "https://example.invalid/domains/categorization/", "", `["www.amazon.com"]`)
+	if err != nil || replayedPost != recordedPost {
+		t.Fatalf("replayed %s (%v), recorded %s", replayedPost, err, recordedPost)
+	}
+
+	// a different body is a different request
+	_, _, err = roundTrip(t, replay, "POST", "https://example.invalid/domains/categorization/", "", `["bibikun.ru"]`)
+	if !errors.Is(err, ErrNoFixture) {
+		t.Fatalf("%v should be %v", err, ErrNoFixture)
+	}
+}
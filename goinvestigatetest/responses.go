@@ -0,0 +1,438 @@
+package goinvestigatetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Canned responses, modelled on the examples in the Investigate API
+// documentation. Format verbs are filled in from the request path.
+
+type categorization struct {
+	Status             int      `json:"status"`
+	ContentCategories  []string `json:"content_categories"`
+	SecurityCategories []string `json:"security_categories"`
+}
+
+// the categorizations the server knows about; any other domain is unknown
+var knownCategorizations = map[string]categorization{
+	"www.amazon.com":  {1, []string{"8"}, []string{}},
+	"www.opendns.com": {1, []string{}, []string{}},
+	"bibikun.ru":      {-1, []string{}, []string{"67"}},
+}
+
+// the labels of the category IDs used in knownCategorizations
+var categoryLabels = map[string]string{
+	"8":  "Ecommerce/Shopping",
+	"67": "Malware",
+}
+
+func labelled(ids []string) []string {
+	labels := make([]string, len(ids))
+	for i, id := range ids {
+		labels[i] = categoryLabels[id]
+	}
+	return labels
+}
+
+func categorizations(domains []string, labels bool) string {
+	resp := make(map[string]categorization, len(domains))
+	for _, domain := range domains {
+		cat, ok := knownCategorizations[domain]
+		if !ok {
+			cat = categorization{0, []string{}, []string{}}
+		}
+		if labels {
+			cat.ContentCategories = labelled(cat.ContentCategories)
+			cat.SecurityCategories = labelled(cat.SecurityCategories)
+		}
+		resp[domain] = cat
+	}
+
+	body, _ := json.Marshal(resp)
+	return string(body)
+}
+
+// args: ip, query type
+const ipRRHistory = `{
+  "rrs": [
+    {"rr": "www.example.com.", "ttl": 86400, "class": "IN", "type": "%[2]s", "name": "%[1]s"},
+    {"rr": "www.example.net.", "ttl": 86400, "class": "IN", "type": "%[2]s", "name": "%[1]s"}
+  ],
+  "features": {
+    "rr_count": 19,
+    "ld2_count": 10,
+    "ld3_count": 14,
+    "ld2_1_count": 7,
+    "ld2_2_count": 11,
+    "div_ld2": 0.5263157894736842,
+    "div_ld3": 0.7368421052631579,
+    "div_ld2_1": 0.3684210526315789,
+    "div_ld2_2": 0.5789473684210527
+  }
+}`
+
+// args: domain, query type
+const domainRRHistory = `{
+  "rrs_tf": [
+    {
+      "first_seen": "2013-07-31",
+      "last_seen": "2013-10-17",
+      "rrs": [
+        {"name": "%[1]s.", "ttl": 86400, "class": "IN", "type": "%[2]s", "rr": "93.184.216.119"}
+      ]
+    }
+  ],
+  "features": {
+    "age": 91,
+    "ttls_min": 86400,
+    "ttls_max": 172800,
+    "ttls_mean": 129600,
+    "ttls_median": 129600,
+    "ttls_stddev": 43200,
+    "country_codes": ["US"],
+    "country_count": 1,
+    "asns": [15133, 40528],
+    "asns_count": 2,
+    "prefixes": ["93.184.208.0", "192.0.43.0"],
+    "prefixes_count": 2,
+    "rips": 2,
+    "div_rips": 1,
+    "locations": [{"lat": 38, "lon": -97}, {"lat": 33.78659999999999, "lon": -118.2987}],
+    "locations_count": 2,
+    "geo_distance_sum": 1970.1616237100388,
+    "geo_distance_mean": 985.0808118550194,
+    "non_routable": false,
+    "mail_exchanger": false,
+    "cname": false,
+    "ff_candidate": false,
+    "rips_stability": 0.5,
+    "base_domain": "example.com",
+    "is_subdomain": false
+  }
+}`
+
+const relatedDomains = `{
+  "tb1": [
+    ["www.example1.com", 10],
+    ["info.example2.com.com", 9],
+    ["support.example.com", 3]
+  ],
+  "found": true
+}`
+
+const cooccurrences = `{
+  "pfs2": [
+    ["download.example.com", 0.9320288065469468],
+    ["query.example.com", 0.06797119345305325]
+  ],
+  "found": true
+}`
+
+const securityFeatures = `{
+  "dga_score": 38.301771886101335,
+  "perplexity": 0.4540313302593146,
+  "entropy": 2.5216406363433186,
+  "securerank2": -1.3135141095601992,
+  "pagerank": 0.0262532,
+  "asn_score": -29.75810625887133,
+  "prefix_score": -64.9070502788884,
+  "rip_score": -75.64720536038982,
+  "popularity": 25.335450495507196,
+  "fastflux": false,
+  "geodiversity": [["UA", 0.24074075], ["IN", 0.018518519]],
+  "geodiversity_normalized": [["AP", 0.3761535390278368], ["US", 0.0005015965168831449]],
+  "tld_geodiversity": [["ID", 0.3848226710420966], ["BY", 0.1127399438411313]],
+  "geoscore": 0,
+  "ks_test": 0,
+  "attack": "",
+  "threat_type": "",
+  "found": true
+}`
+
+// args: domain
+const domainTags = `[
+  {
+    "period": {"begin": "2014-04-07", "end": "Current"},
+    "category": "Malware",
+    "url": "http://%[1]s/"
+  },
+  {
+    "period": {"begin": "2014-03-04", "end": "2014-03-05"},
+    "category": "Malware",
+    "url": "http://%[1]s/34/45791.html"
+  }
+]`
+
+const latestDomains = `[
+  {"id": 22842894, "name": "www.cxhyly.com"},
+  {"id": 22958747, "name": "cxhyly.com"}
+]`
+
+// args: domain
+const whoisRecord = `{
+  "domainName": "%[1]s",
+  "registrarName": "MarkMonitor Inc.",
+  "registrarIANAID": "292",
+  "created": "1997-09-15",
+  "updated": "2015-06-12",
+  "expires": "2020-09-13",
+  "status": ["clientDeleteProhibited", "clientTransferProhibited"],
+  "nameServers": ["ns1.%[1]s", "ns2.%[1]s"],
+  "whoisServers": "whois.markmonitor.com",
+  "emails": ["dns-admin@%[1]s"],
+  "addresses": ["1600 Amphitheatre Parkway"],
+  "recordExpired": false,
+  "hasRawText": true,
+  "auditUpdatedDate": "2015-06-13 00:00:00.000 UTC",
+  "timestamp": null,
+  "timeOfLatestRealtimeCheck": 1434125000000,
+  "registrantName": "DNS Admin",
+  "registrantOrganization": "Example Inc.",
+  "registrantEmail": "dns-admin@%[1]s",
+  "registrantStreet": ["1600 Amphitheatre Parkway"],
+  "registrantCity": "Mountain View",
+  "registrantState": "CA",
+  "registrantPostalCode": "94043",
+  "registrantCountry": "UNITED STATES",
+  "registrantTelephone": "16502530000",
+  "technicalContactName": "DNS Admin",
+  "technicalContactEmail": "dns-admin@%[1]s"
+}`
+
+// the number of records and domains the paged WHOIS endpoints have
+const whoisResults = 5
+
+// The page of items the request asks for, by its limit and offset.
+func pageBounds(r *http.Request, total int) (int, int) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = total
+	}
+	start := min(offset, total)
+	return start, min(start+limit, total)
+}
+
+func whoisHistory(domain string, r *http.Request) string {
+	start, end := pageBounds(r, whoisResults)
+	records := make([]json.RawMessage, 0, end-start)
+	for i := start; i < end; i++ {
+		records = append(records, json.RawMessage(fmt.Sprintf(whoisRecord, domain)))
+	}
+	b, _ := json.Marshal(records)
+	return string(b)
+}
+
+// a page of the domains registered with an email address or name server
+func whoisDomains(key string, r *http.Request) string {
+	type domain struct {
+		Domain  string `json:"domain"`
+		Current bool   `json:"current"`
+	}
+	type page struct {
+		TotalResults      int      `json:"totalResults"`
+		MoreDataAvailable bool     `json:"moreDataAvailable"`
+		Limit             int      `json:"limit"`
+		Domains           []domain `json:"domains"`
+	}
+
+	start, end := pageBounds(r, whoisResults)
+	resp := page{
+		TotalResults:      whoisResults,
+		MoreDataAvailable: end < whoisResults,
+		Limit:             end - start,
+		Domains:           []domain{},
+	}
+	for i := start; i < end; i++ {
+		resp.Domains = append(resp.Domains, domain{fmt.Sprintf("example%d.com", i), i%2 == 0})
+	}
+	b, _ := json.Marshal(map[string]page{key: resp})
+	return string(b)
+}
+
+// the passive DNS records the server has for every name, IP and query
+var pdnsRecords = []struct {
+	Type string
+	RR   string
+}{
+	{"A", "93.184.216.34"},
+	{"A", "93.184.216.119"},
+	{"AAAA", "2606:2800:220:1:248:1893:25c8:1946"},
+	{"MX", "10 mail.example.com."},
+	{"TXT", `"v=spf1 -all"`},
+}
+
+// a page of passive DNS records of the given name, filtered by the
+// request's recordType
+func pdnsResult(name string, r *http.Request) string {
+	type record struct {
+		Name               string   `json:"name"`
+		Type               string   `json:"type"`
+		RR                 string   `json:"rr"`
+		MinTTL             int      `json:"minTtl"`
+		MaxTTL             int      `json:"maxTtl"`
+		FirstSeen          int64    `json:"firstSeen"`
+		LastSeen           int64    `json:"lastSeen"`
+		FirstSeenISO       string   `json:"firstSeenISO"`
+		LastSeenISO        string   `json:"lastSeenISO"`
+		SecurityCategories []string `json:"securityCategories"`
+		ContentCategories  []string `json:"contentCategories"`
+	}
+	type pageInfo struct {
+		HasMoreRecords  bool `json:"hasMoreRecords"`
+		Offset          int  `json:"offset"`
+		Limit           int  `json:"limit"`
+		TotalNumRecords int  `json:"totalNumRecords"`
+	}
+
+	var types []string
+	if recordType := r.URL.Query().Get("recordType"); recordType != "" {
+		types = strings.Split(recordType, ",")
+	}
+
+	var records []record
+	for i, rec := range pdnsRecords {
+		if types != nil && !slices.Contains(types, rec.Type) {
+			continue
+		}
+		// a day apart, starting 2020-01-01
+		first := int64(1577836800 + i*86400)
+		records = append(records, record{
+			Name:               name + ".",
+			Type:               rec.Type,
+			RR:                 rec.RR,
+			MinTTL:             300,
+			MaxTTL:             3600,
+			FirstSeen:          first,
+			LastSeen:           first + 86400,
+			FirstSeenISO:       time.Unix(first, 0).UTC().Format("2006-01-02T15:04Z"),
+			LastSeenISO:        time.Unix(first+86400, 0).UTC().Format("2006-01-02T15:04Z"),
+			SecurityCategories: []string{},
+			ContentCategories:  []string{},
+		})
+	}
+
+	start, end := pageBounds(r, len(records))
+	b, _ := json.Marshal(map[string]interface{}{
+		"pageInfo": pageInfo{end < len(records), start, end - start, len(records)},
+		"records":  append([]record{}, records[start:end]...),
+	})
+	return string(b)
+}
+
+const pdnsTimeline = `[
+  {"categories": [], "attacks": [], "threatTypes": [], "timestamp": 1577836800000},
+  {"categories": ["Malware"], "attacks": ["Neutrino"], "threatTypes": ["Exploit Kit"], "timestamp": 1580515200000}
+]`
+
+const riskScore = `{
+  "risk_score": 82,
+  "indicators": [
+    {"indicator": "Geo Popularity Score", "indicator_id": "Geo Popularity Score", "normalized_score": 45, "score": -0.4},
+    {"indicator": "Keyword Score", "indicator_id": "Keyword Score", "normalized_score": 4, "score": 0.0123},
+    {"indicator": "Lexical", "indicator_id": "Lexical", "normalized_score": 43, "score": 0.043},
+    {"indicator": "Popularity 1 Day", "indicator_id": "Popularity 1 Day", "normalized_score": 100, "score": 0},
+    {"indicator": "Threat Type", "indicator_id": "Threat Type", "normalized_score": 100, "score": 1}
+  ]
+}`
+
+// the number of subdomains the server has for every domain
+const subdomainCount = 250
+
+// a page of the subdomains of the given domain, named "sub000" to "sub249"
+// so that they sort by number, starting after the request's offsetName
+func subdomains(domain string, r *http.Request) string {
+	type subdomain struct {
+		Name               string   `json:"name"`
+		FirstSeen          string   `json:"firstSeen"`
+		SecurityCategories []string `json:"securityCategories"`
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	start := 0
+	if after := r.URL.Query().Get("offsetName"); after != "" {
+		var n int
+		if _, err := fmt.Sscanf(after, "sub%03d.", &n); err == nil {
+			start = n + 1
+		}
+	}
+
+	resp := []subdomain{}
+	for i := start; i < subdomainCount && len(resp) < limit; i++ {
+		resp = append(resp, subdomain{
+			Name:               fmt.Sprintf("sub%03d.%s", i, domain),
+			FirstSeen:          strconv.Itoa(1548797839 + i*3600),
+			SecurityCategories: []string{},
+		})
+	}
+	b, _ := json.Marshal(resp)
+	return string(b)
+}
+
+const asForIP = `[
+  {
+    "cidr": "208.67.216.0/21",
+    "asn": 36692,
+    "ir": 3,
+    "description": "OPENDNS - OpenDNS, LLC 86400",
+    "creation_date": "2006-06-13"
+  }
+]`
+
+const prefixesForASN = `[
+  {
+    "cidr": "204.194.232.0/21",
+    "geo": {
+      "country_name": "United States",
+      "country_code": 840
+    }
+  },
+  {
+    "cidr": "146.112.62.0/24",
+    "geo": {
+      "country_name": "United Kingdom",
+      "country_code": 826
+    }
+  },
+  {
+    "cidr": "2620:119:35::/48",
+    "geo": {
+      "country_name": "United States",
+      "country_code": 840
+    }
+  }
+]`
+
+// hourly query counts for a domain between the request's start and stop,
+// which default to the week before the current hour. The counts follow a
+// daily cycle.
+func domainVolume(r *http.Request) string {
+	stop := time.Now().UTC().Truncate(time.Hour)
+	start := stop.Add(-7 * 24 * time.Hour)
+	if ms, err := strconv.ParseInt(r.URL.Query().Get("stop"), 10, 64); err == nil {
+		stop = time.UnixMilli(ms).UTC().Truncate(time.Hour)
+	}
+	if ms, err := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64); err == nil {
+		start = time.UnixMilli(ms).UTC().Truncate(time.Hour)
+	}
+
+	queries := []int{}
+	for t := start; !t.After(stop); t = t.Add(time.Hour) {
+		queries = append(queries, 100+t.Hour()*10)
+	}
+	b, _ := json.Marshal(map[string]interface{}{
+		"dates":   []int64{start.UnixMilli(), stop.UnixMilli()},
+		"queries": queries,
+	})
+	return string(b)
+}
@@ -0,0 +1,309 @@
+/*
+Package goinvestigatetest provides a local HTTP stand-in for the Investigate
+API, for testing code which uses goinvestigate without an API key or network
+access.
+
+The server answers every endpoint the client wraps with canned responses of
+the same shape as the real API's, and rejects requests which don't carry its
+key:
+
+	srv := goinvestigatetest.NewServer()
+	defer srv.Close()
+
+	inv := goinvestigate.New(srv.Key, goinvestigate.WithBaseURL(srv.URL))
+
+Faults can be injected to exercise error handling and retries:
+
+	srv.Inject("/security/", goinvestigatetest.RateLimitFault(time.Second, 2))
+*/
+package goinvestigatetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The API key which a Server accepts, unless it is changed.
+const DefaultKey = "00000000-0000-0000-0000-000000000000"
+
+// A Fault changes how a Server responds to requests.
+type Fault struct {
+	// Respond with this HTTP status, if it is non-zero.
+	Status int
+	// Extra headers to send, e.g. Retry-After.
+	Header http.Header
+	// Respond with this body instead of the endpoint's usual one.
+	Body string
+	// Wait this long before responding.
+	Delay time.Duration
+	// Apply the fault to this many requests, then stop. Zero means every
+	// request.
+	Times int
+}
+
+// A fault which makes requests fail with the given HTTP status.
+func ErrorFault(status int, times int) Fault {
+	return Fault{
+		Status: status,
+		Body:   fmt.Sprintf(`{"errorMessage": %q}`, http.StatusText(status)),
+		Times:  times,
+	}
+}
+
+// A fault which makes requests fail with 429 Too Many Requests, asking the
+// client to retry after the given time.
+func RateLimitFault(retryAfter time.Duration, times int) Fault {
+	f := ErrorFault(http.StatusTooManyRequests, times)
+	f.Header = http.Header{"Retry-After": []string{fmt.Sprint(int(retryAfter.Seconds()))}}
+	return f
+}
+
+// A fault which delays responses by the given time.
+func SlowFault(delay time.Duration, times int) Fault {
+	return Fault{Delay: delay, Times: times}
+}
+
+// A fault which responds with a body which isn't valid JSON.
+func MalformedFault(times int) Fault {
+	return Fault{Body: `{"malformed": `, Times: times}
+}
+
+type injectedFault struct {
+	prefix string
+	Fault
+}
+
+// A Request is a request which a Server received.
+type Request struct {
+	Method string
+	// The path and query of the request
+	URI  string
+	Body string
+}
+
+// Server is a local stand-in for the Investigate API. It is safe for
+// concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// The API key the server accepts. Requests with any other key are
+	// rejected with 401 Unauthorized.
+	Key string
+
+	mu        sync.Mutex
+	faults    []*injectedFault
+	responses map[string]string
+	requests  []Request
+}
+
+// Start a new Server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		Key:       DefaultKey,
+		responses: make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Make requests whose path starts with prefix respond according to f.
+// Faults apply in the order they were injected; the first one which
+// matches a request and hasn't been used up is applied to it.
+func (s *Server) Inject(prefix string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &injectedFault{prefix, f})
+}
+
+// Remove all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Respond to requests for the given path with body instead of the canned
+// response.
+func (s *Server) SetResponse(path string, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[path] = body
+}
+
+// Requests returns the requests the server has received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Finds the fault to apply to a request for path, and uses it up.
+func (s *Server) takeFault(path string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if !strings.HasPrefix(path, f.prefix) {
+			continue
+		}
+
+		fault := f.Fault
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return &fault
+	}
+
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{r.Method, r.URL.RequestURI(), string(body)})
+	override, overridden := s.responses[r.URL.Path]
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-Id", fmt.Sprintf("goinvestigatetest-%d", len(s.Requests())))
+
+	if r.Header.Get("Authorization") != "Bearer "+s.Key {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"errorMessage": "Invalid authentication credentials"}`)
+		return
+	}
+
+	if fault := s.takeFault(r.URL.Path); fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		for k, v := range fault.Header {
+			w.Header()[k] = v
+		}
+		if fault.Status != 0 {
+			w.WriteHeader(fault.Status)
+		}
+		if fault.Body != "" || fault.Status != 0 {
+			fmt.Fprint(w, fault.Body)
+			return
+		}
+	}
+
+	if overridden {
+		fmt.Fprint(w, override)
+		return
+	}
+
+	for _, route := range routes {
+		if route.method != r.Method {
+			continue
+		}
+		if m := route.pattern.FindStringSubmatch(r.URL.Path); m != nil {
+			status, resp := route.respond(m[1:], r, body)
+			w.WriteHeader(status)
+			fmt.Fprint(w, resp)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+	fmt.Fprint(w, `{"errorMessage": "Not Found"}`)
+}
+
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	// builds the response from the path's submatches
+	respond func(args []string, r *http.Request, body []byte) (int, string)
+}
+
+func get(pattern string, respond func(args []string) string) route {
+	return route{"GET", regexp.MustCompile("^" + pattern + "$"), func(args []string, r *http.Request, body []byte) (int, string) {
+		return http.StatusOK, respond(args)
+	}}
+}
+
+var routes = []route{
+	get(`/dnsdb/ip/([^/]+)/([^/]+)\.json`, func(args []string) string {
+		return fmt.Sprintf(ipRRHistory, args[1], args[0])
+	}),
+	get(`/dnsdb/name/([^/]+)/([^/]+)\.json`, func(args []string) string {
+		return fmt.Sprintf(domainRRHistory, args[1], args[0])
+	}),
+	{"GET", regexp.MustCompile(`^/domains/categorization/([^/]+)$`), func(args []string, r *http.Request, body []byte) (int, string) {
+		return http.StatusOK, categorizations([]string{args[0]}, r.URL.Query().Get("showLabels") != "")
+	}},
+	{"POST", regexp.MustCompile(`^/domains/categorization/?$`), func(args []string, r *http.Request, body []byte) (int, string) {
+		var domains []string
+		if err := json.Unmarshal(body, &domains); err != nil {
+			return http.StatusBadRequest, `{"errorMessage": "Invalid request body"}`
+		}
+		return http.StatusOK, categorizations(domains, r.URL.Query().Get("showLabels") != "")
+	}},
+	get(`/links/name/([^/]+)\.json`, func(args []string) string {
+		return relatedDomains
+	}),
+	get(`/recommendations/name/([^/]+)\.json`, func(args []string) string {
+		return cooccurrences
+	}),
+	get(`/security/name/([^/]+)\.json`, func(args []string) string {
+		return securityFeatures
+	}),
+	get(`/domains/([^/]+)/latest_tags`, func(args []string) string {
+		return fmt.Sprintf(domainTags, args[0])
+	}),
+	get(`/ips/([^/]+)/latest_domains`, func(args []string) string {
+		return latestDomains
+	}),
+	get(`/domains/categories`, func(args []string) string {
+		b, _ := json.Marshal(categoryLabels)
+		return string(b)
+	}),
+	{"GET", regexp.MustCompile(`^/whois/emails/([^/]+)$`), func(args []string, r *http.Request, body []byte) (int, string) {
+		return http.StatusOK, whoisDomains(args[0], r)
+	}},
+	{"GET", regexp.MustCompile(`^/whois/nameservers/([^/]+)$`), func(args []string, r *http.Request, body []byte) (int, string) {
+		return http.StatusOK, whoisDomains(args[0], r)
+	}},
+	{"GET", regexp.MustCompile(`^/whois/([^/]+)/history$`), func(args []string, r *http.Request, body []byte) (int, string) {
+		return http.StatusOK, whoisHistory(args[0], r)
+	}},
+	get(`/whois/([^/]+)`, func(args []string) string {
+		return fmt.Sprintf(whoisRecord, args[0])
+	}),
+	{"GET", regexp.MustCompile(`^/pdns/(?:domain|name|ip|raw)/([^/]+)$`), func(args []string, r *http.Request, body []byte) (int, string) {
+		return http.StatusOK, pdnsResult(args[0], r)
+	}},
+	get(`/pdns/timeline/([^/]+)`, func(args []string) string {
+		return pdnsTimeline
+	}),
+	get(`/domains/risk-score/([^/]+)`, func(args []string) string {
+		return riskScore
+	}),
+	{"GET", regexp.MustCompile(`^/subdomains/([^/]+)$`), func(args []string, r *http.Request, body []byte) (int, string) {
+		return http.StatusOK, subdomains(args[0], r)
+	}},
+	get(`/bgp_routes/ip/([^/]+)/as_for_ip\.json`, func(args []string) string {
+		return asForIP
+	}),
+	get(`/bgp_routes/asn/([0-9]+)/prefixes_for_asn\.json`, func(args []string) string {
+		return prefixesForASN
+	}),
+	{"GET", regexp.MustCompile(`^/domains/volume/([^/]+)$`), func(args []string, r *http.Request, body []byte) (int, string) {
+		return http.StatusOK, domainVolume(r)
+	}},
+}
@@ -0,0 +1,155 @@
+package goinvestigatetest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fetch(t *testing.T, srv *Server, key string, path string) (int, string) {
+	req, err := http.NewRequest("GET", srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestServerRoutes(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	paths := []string{
+		"/dnsdb/ip/A/208.64.121.161.json",
+		"/dnsdb/name/A/bibikun.ru.json",
+		"/domains/categorization/www.amazon.com?showLabels=true",
+		"/links/name/www.test.com.json",
+		"/recommendations/name/www.test.com.json",
+		"/security/name/www.test.com.json",
+		"/domains/bibikun.ru/latest_tags",
+		"/ips/46.161.41.43/latest_domains",
+		"/domains/categories",
+		"/whois/example.com",
+		"/whois/example.com/history?limit=2",
+		"/whois/emails/dns-admin@example.com",
+		"/whois/nameservers/ns1.example.com?offset=3",
+		"/pdns/domain/example.com?recordType=A,MX&limit=1",
+		"/pdns/ip/93.184.216.34",
+		"/pdns/timeline/example.com",
+		"/domains/risk-score/bibikun.ru",
+		"/subdomains/example.com?offsetName=sub099.example.com&limit=10",
+		"/bgp_routes/ip/208.67.222.222/as_for_ip.json",
+		"/bgp_routes/asn/36692/prefixes_for_asn.json",
+		"/domains/volume/example.com?start=1513278000000&stop=1513447200000&match=all",
+	}
+
+	for _, path := range paths {
+		status, body := fetch(t, srv, srv.Key, path)
+		if status != http.StatusOK {
+			t.Fatalf("%s: status %d", path, status)
+		}
+		if !json.Valid([]byte(body)) {
+			t.Fatalf("%s: invalid JSON %s", path, body)
+		}
+	}
+
+	if status, _ := fetch(t, srv, srv.Key, "/no/such/endpoint"); status != http.StatusNotFound {
+		t.Fatalf("unknown path: status %d", status)
+	}
+
+	if len(srv.Requests()) != len(paths)+1 {
+		t.Fatalf("recorded %d requests", len(srv.Requests()))
+	}
+}
+
+func TestServerCategorizations(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	req, _ := http.NewRequest("POST", srv.URL+"/domains/categorization/?showLabels=true",
+		strings.NewReader(`["www.amazon.com", "bibikun.ru", "unknown.com"]`))
+	req.Header.Set("Authorization", "Bearer "+srv.Key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out map[string]categorization
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out["www.amazon.com"].ContentCategories[0] != "Ecommerce/Shopping" ||
+		out["bibikun.ru"].Status != -1 ||
+		out["bibikun.ru"].SecurityCategories[0] != "Malware" ||
+		out["unknown.com"].Status != 0 {
+		t.Fatalf("unexpected categorizations %v", out)
+	}
+}
+
+func TestServerAuth(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	if status, _ := fetch(t, srv, "bad_key", "/security/name/www.test.com.json"); status != http.StatusUnauthorized {
+		t.Fatalf("bad key: status %d", status)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	t.Parallel()
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.Inject("/security/", RateLimitFault(3*time.Second, 2))
+	srv.Inject("/security/", ErrorFault(http.StatusInternalServerError, 1))
+	srv.Inject("/links/", MalformedFault(0))
+	srv.SetResponse("/ips/8.8.8.8/latest_domains", `[]`)
+
+	path := "/security/name/www.test.com.json"
+	for i, ref := range []int{429, 429, 500, 200} {
+		if status, _ := fetch(t, srv, srv.Key, path); status != ref {
+			t.Fatalf("request %d: status %d should be %d", i, status, ref)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, body := fetch(t, srv, srv.Key, "/links/name/www.test.com.json"); json.Valid([]byte(body)) {
+			t.Fatalf("response should be malformed: %s", body)
+		}
+	}
+
+	if _, body := fetch(t, srv, srv.Key, "/ips/8.8.8.8/latest_domains"); body != `[]` {
+		t.Fatalf("response should be overridden: %s", body)
+	}
+
+	srv.ClearFaults()
+	if status, _ := fetch(t, srv, srv.Key, "/links/name/www.test.com.json"); status != http.StatusOK {
+		t.Fatalf("faults should be cleared: status %d", status)
+	}
+
+	srv.Inject("", SlowFault(50*time.Millisecond, 1))
+	start := time.Now()
+	fetch(t, srv, srv.Key, path)
+	if time.Since(start) < 50*time.Millisecond {
+		t.Fatal("response should be delayed")
+	}
+}
@@ -0,0 +1,109 @@
+package goinvestigate
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Investigator is the set of queries which can be made with an Investigate
+// client. Code which depends on an Investigator rather than on *Investigate
+// can be tested without making live API calls, e.g. with the in-memory fake
+// in the goinvestigatefake package.
+type Investigator interface {
+	Categorization(domain string, labels bool) (*DomainCategorization, error)
+	CategorizationContext(ctx context.Context, domain string, labels bool) (*DomainCategorization, error)
+	Categorizations(domains []string, labels bool) (map[string]DomainCategorization, error)
+	CategorizationsContext(ctx context.Context, domains []string, labels bool) (map[string]DomainCategorization, error)
+	RelatedDomains(domain string) ([]RelatedDomain, error)
+	RelatedDomainsContext(ctx context.Context, domain string) ([]RelatedDomain, error)
+	Cooccurrences(domain string) ([]Cooccurrence, error)
+	CooccurrencesContext(ctx context.Context, domain string) ([]Cooccurrence, error)
+	RelatedDomainsResult(domain string) (*RelatedDomainsResult, error)
+	RelatedDomainsResultContext(ctx context.Context, domain string) (*RelatedDomainsResult, error)
+	CooccurrencesResult(domain string) (*CooccurrencesResult, error)
+	CooccurrencesResultContext(ctx context.Context, domain string) (*CooccurrencesResult, error)
+	Security(domain string) (*SecurityFeatures, error)
+	SecurityContext(ctx context.Context, domain string) (*SecurityFeatures, error)
+	DomainTags(domain string) ([]DomainTag, error)
+	DomainTagsContext(ctx context.Context, domain string) ([]DomainTag, error)
+	IpRRHistory(ip string, queryType QueryType) (*IPRRHistory, error)
+	IpRRHistoryContext(ctx context.Context, ip string, queryType QueryType) (*IPRRHistory, error)
+	DomainRRHistory(domain string, queryType QueryType) (*DomainRRHistory, error)
+	DomainRRHistoryContext(ctx context.Context, domain string, queryType QueryType) (*DomainRRHistory, error)
+	LatestDomains(ip string) ([]string, error)
+	LatestDomainsContext(ctx context.Context, ip string) ([]string, error)
+	Categories() (map[string]string, error)
+	CategoriesContext(ctx context.Context) (map[string]string, error)
+	Whois(domain string) (*WhoisRecord, error)
+	WhoisContext(ctx context.Context, domain string) (*WhoisRecord, error)
+	WhoisHistory(domain string, page Page) ([]WhoisRecord, error)
+	WhoisHistoryContext(ctx context.Context, domain string, page Page) ([]WhoisRecord, error)
+	WhoisByEmail(email string, page Page) (*WhoisDomains, error)
+	WhoisByEmailContext(ctx context.Context, email string, page Page) (*WhoisDomains, error)
+	WhoisByNameserver(nameserver string, page Page) (*WhoisDomains, error)
+	WhoisByNameserverContext(ctx context.Context, nameserver string, page Page) (*WhoisDomains, error)
+	PDNSDomain(domain string, opts PDNSOptions) (*PDNSResult, error)
+	PDNSDomainContext(ctx context.Context, domain string, opts PDNSOptions) (*PDNSResult, error)
+	PDNSName(name string, opts PDNSOptions) (*PDNSResult, error)
+	PDNSNameContext(ctx context.Context, name string, opts PDNSOptions) (*PDNSResult, error)
+	PDNSIP(ip string, opts PDNSOptions) (*PDNSResult, error)
+	PDNSIPContext(ctx context.Context, ip string, opts PDNSOptions) (*PDNSResult, error)
+	PDNSRaw(query string, opts PDNSOptions) (*PDNSResult, error)
+	PDNSRawContext(ctx context.Context, query string, opts PDNSOptions) (*PDNSResult, error)
+	PDNSTimeline(domain string) ([]PDNSTimelineEntry, error)
+	PDNSTimelineContext(ctx context.Context, domain string) ([]PDNSTimelineEntry, error)
+	RiskScore(domain string) (*DomainRiskScore, error)
+	RiskScoreContext(ctx context.Context, domain string) (*DomainRiskScore, error)
+	SubdomainsPage(domain string, after string, limit int) ([]Subdomain, error)
+	SubdomainsPageContext(ctx context.Context, domain string, after string, limit int) ([]Subdomain, error)
+	ASForIP(ip string) ([]ASInfo, error)
+	ASForIPContext(ctx context.Context, ip string) ([]ASInfo, error)
+	PrefixesForASN(asn int) ([]ASPrefix, error)
+	PrefixesForASNContext(ctx context.Context, asn int) ([]ASPrefix, error)
+	DomainVolume(domain string, start, stop time.Time, match VolumeMatch) (*DomainVolume, error)
+	DomainVolumeContext(ctx context.Context, domain string, start, stop time.Time, match VolumeMatch) (*DomainVolume, error)
+
+	// many lookups at once
+	SecurityBulk(domains []string) []Result[*SecurityFeatures]
+	SecurityBulkContext(ctx context.Context, domains []string) []Result[*SecurityFeatures]
+	RelatedDomainsBulk(domains []string) []Result[[]RelatedDomain]
+	RelatedDomainsBulkContext(ctx context.Context, domains []string) []Result[[]RelatedDomain]
+	CooccurrencesBulk(domains []string) []Result[[]Cooccurrence]
+	CooccurrencesBulkContext(ctx context.Context, domains []string) []Result[[]Cooccurrence]
+	DomainTagsBulk(domains []string) []Result[[]DomainTag]
+	DomainTagsBulkContext(ctx context.Context, domains []string) []Result[[]DomainTag]
+	DomainRRHistoryBulk(domains []string, queryType QueryType) []Result[*DomainRRHistory]
+	DomainRRHistoryBulkContext(ctx context.Context, domains []string, queryType QueryType) []Result[*DomainRRHistory]
+	IpRRHistoryBulk(ips []string, queryType QueryType) []Result[*IPRRHistory]
+	IpRRHistoryBulkContext(ctx context.Context, ips []string, queryType QueryType) []Result[*IPRRHistory]
+	LatestDomainsBulk(ips []string) []Result[[]string]
+	LatestDomainsBulkContext(ctx context.Context, ips []string) []Result[[]string]
+	RiskScoreBulk(domains []string) []Result[*DomainRiskScore]
+	RiskScoreBulkContext(ctx context.Context, domains []string) []Result[*DomainRiskScore]
+
+	// streams of lookups
+	SecurityStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[*SecurityFeatures]]
+	RelatedDomainsStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[[]RelatedDomain]]
+	CooccurrencesStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[[]Cooccurrence]]
+	DomainTagsStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[[]DomainTag]]
+	DomainRRHistoryStream(ctx context.Context, domains []string, queryType QueryType, opts ...StreamOption) iter.Seq2[string, Result[*DomainRRHistory]]
+	IpRRHistoryStream(ctx context.Context, ips []string, queryType QueryType, opts ...StreamOption) iter.Seq2[string, Result[*IPRRHistory]]
+	LatestDomainsStream(ctx context.Context, ips []string, opts ...StreamOption) iter.Seq2[string, Result[[]string]]
+	RiskScoreStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[*DomainRiskScore]]
+
+	// iterators over every page, and every query type
+	PDNSDomainAll(ctx context.Context, domain string, opts PDNSOptions) iter.Seq2[PDNSRecord, error]
+	PDNSNameAll(ctx context.Context, name string, opts PDNSOptions) iter.Seq2[PDNSRecord, error]
+	PDNSIPAll(ctx context.Context, ip string, opts PDNSOptions) iter.Seq2[PDNSRecord, error]
+	PDNSRawAll(ctx context.Context, query string, opts PDNSOptions) iter.Seq2[PDNSRecord, error]
+	DomainRRHistoryAllTypes(domain string) (*DomainRRHistory, error)
+	DomainRRHistoryAllTypesContext(ctx context.Context, domain string) (*DomainRRHistory, error)
+	Subdomains(domain string) iter.Seq2[Subdomain, error]
+	SubdomainsContext(ctx context.Context, domain string) iter.Seq2[Subdomain, error]
+	WhoisHistoryAll(ctx context.Context, domain string) iter.Seq2[WhoisRecord, error]
+	WhoisByEmailAll(ctx context.Context, email string) iter.Seq2[WhoisDomain, error]
+	WhoisByNameserverAll(ctx context.Context, nameserver string) iter.Seq2[WhoisDomain, error]
+}
+
+var _ Investigator = (*Investigate)(nil)
@@ -0,0 +1,251 @@
+package goinvestigate
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Returned, wrapped in a *ValidationError, when a domain or IP given to a
+// query method isn't valid. No request is made.
+var ErrInvalidInput = errors.New("invalid input")
+
+// A ValidationError describes a domain or IP which was rejected before
+// making a request.
+type ValidationError struct {
+	// What the input should have been, e.g. "domain" or "IP"
+	Kind   string
+	Input  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %s %q: %s", ErrInvalidInput, e.Kind, e.Input, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// the longest a domain name can be in text form, without its trailing dot
+const maxDomainLength = 253
+
+// Normalize a domain the way the query methods do before using it in a
+// request: surrounding space and a trailing dot are removed, the host is
+// taken out of a URL (e.g. "https://www.test.com/path" gives
+// "www.test.com", but "www.test.com/path" is rejected), it is lowercased, and internationalized labels are
+// converted to punycode. Labels must only have letters, digits, hyphens and
+// underscores, and must not start or end with a hyphen.
+//
+// Internationalized labels are lowercased, but not otherwise normalized as
+// IDNA would, so they should be given in their usual (NFC) form.
+func NormalizeDomain(domain string) (string, error) {
+	invalid := func(reason string) (string, error) {
+		return "", &ValidationError{Kind: "domain", Input: domain, Reason: reason}
+	}
+
+	if !utf8.ValidString(domain) {
+		return invalid("invalid UTF-8")
+	}
+
+	host := strings.TrimSpace(domain)
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return invalid("not a domain or URL")
+		}
+		host = u.Hostname()
+	} else if strings.ContainsAny(host, "/?#") {
+		// likely a typo, which would otherwise query a different domain
+		return invalid("has a path, query or fragment but no scheme")
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return invalid("empty")
+	}
+
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		ascii, err := toASCII(label)
+		if err != nil {
+			return invalid(err.Error())
+		}
+		labels[i] = ascii
+	}
+
+	host = strings.Join(labels, ".")
+	if len(host) > maxDomainLength {
+		return invalid("too long")
+	}
+	return host, nil
+}
+
+// Validates a single label, converting it to punycode if it isn't ASCII.
+func toASCII(label string) (string, error) {
+	if label == "" {
+		return "", errors.New("empty label")
+	}
+
+	for _, r := range label {
+		if r >= utf8.RuneSelf {
+			encoded, err := punycode(label)
+			if err != nil {
+				return "", err
+			}
+			label = "xn--" + encoded
+			break
+		}
+	}
+
+	if len(label) > 63 {
+		return "", fmt.Errorf("label %q is too long", label)
+	}
+
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return "", fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return "", fmt.Errorf("label %q has an invalid character %q", label, c)
+		}
+	}
+	return label, nil
+}
+
+// Normalize an IPv4 or IPv6 address the way the query methods do before
+// using it in a request: surrounding space and brackets are removed, and
+// the address is written in its canonical form, e.g. "2001:db8::1".
+func NormalizeIP(ip string) (string, error) {
+	addr := strings.TrimSpace(ip)
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+
+	parsed, err := netip.ParseAddr(addr)
+	if err != nil || parsed.Zone() != "" {
+		return "", &ValidationError{Kind: "IP", Input: ip, Reason: "not an IPv4 or IPv6 address"}
+	}
+	return parsed.Unmap().String(), nil
+}
+
+// Normalizes domain, and escapes it for use as a path segment.
+func domainSegment(domain string) (string, error) {
+	normalized, err := NormalizeDomain(domain)
+	if err != nil {
+		return "", err
+	}
+	return url.PathEscape(normalized), nil
+}
+
+// Normalizes ip, and escapes it for use as a path segment.
+func ipSegment(ip string) (string, error) {
+	normalized, err := NormalizeIP(ip)
+	if err != nil {
+		return "", err
+	}
+	return url.PathEscape(normalized), nil
+}
+
+// Parameters of the punycode bootstring encoding, from RFC 3492.
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+// Encodes s with punycode (RFC 3492), without the "xn--" prefix.
+func punycode(s string) (string, error) {
+	input := []rune(s)
+	var out []byte
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+		}
+	}
+
+	basic := len(out)
+	handled := basic
+	if basic > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := rune(punyInitialN), 0, punyInitialBias
+	for handled < len(input) {
+		// the smallest code point which hasn't been handled yet
+		m := rune(utf8.MaxRune + 1)
+		for _, r := range input {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+
+		if int(m-n) > (1<<31-1-delta)/(handled+1) {
+			return "", errors.New("punycode overflow")
+		}
+		delta += int(m-n) * (handled + 1)
+		n = m
+
+		for _, r := range input {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := k - bias
+				if t < punyTMin {
+					t = punyTMin
+				} else if t > punyTMax {
+					t = punyTMax
+				}
+				if q < t {
+					break
+				}
+				out = append(out, punyDigit(t+(q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			out = append(out, punyDigit(q))
+
+			bias = punyAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return string(out), nil
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
@@ -0,0 +1,91 @@
+package goinvestigate
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"www.test.com":                    "www.test.com",
+		" WWW.Test.COM. ":                 "www.test.com",
+		"https://www.test.com:8443/a?b#c": "www.test.com",
+		"_dmarc.test.com":                 "_dmarc.test.com",
+		"bücher.example":                  "xn--bcher-kva.example",
+		"MÜNCHEN.de":                      "xn--mnchen-3ya.de",
+		"例え.テスト":                          "xn--r8jz45g.xn--zckzah",
+		"ليهمابتكلموشعربي؟":               "xn--egbpdaj6bu4bxfgehfvwxn",
+	}
+
+	for in, ref := range tests {
+		out, err := NormalizeDomain(in)
+		if err != nil || out != ref {
+			t.Fatalf("%q: got %q, %v; should be %q", in, out, err, ref)
+		}
+	}
+
+	for _, in := range []string{"", ".", "a..b", "-a.com", "a b.com", "a%2f.com", "../../ips", "\xff.com",
+		"foo/bar", "www.test.com/path", "www.test.com?q", "www.test.com#frag"} {
+		var verr *ValidationError
+		if _, err := NormalizeDomain(in); !errors.As(err, &verr) || !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("%q: %v should be a *ValidationError", in, err)
+		}
+	}
+}
+
+func TestNormalizeIP(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"208.64.121.161":        "208.64.121.161",
+		" [2001:DB8:0:0::1] ":   "2001:db8::1",
+		"::ffff:208.64.121.161": "208.64.121.161",
+	}
+
+	for in, ref := range tests {
+		out, err := NormalizeIP(in)
+		if err != nil || out != ref {
+			t.Fatalf("%q: got %q, %v; should be %q", in, out, err, ref)
+		}
+	}
+
+	for _, in := range []string{"", "208.64.121", "www.test.com", "fe80::1%eth0", "1.2.3.4/../x"} {
+		if _, err := NormalizeIP(in); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("%q: %v should be %v", in, err, ErrInvalidInput)
+		}
+	}
+}
+
+func TestValidationBeforeRequest(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	valInv := New("test_key", WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return nil, errors.New("no requests should be made")
+	})))
+
+	if _, err := valInv.LatestDomains("1.2.3.4/../../x"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("%v should be %v", err, ErrInvalidInput)
+	}
+
+	if _, err := valInv.Categorizations([]string{"bad domain", "-bad-.com"}, false); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("%v should be %v", err, ErrInvalidInput)
+	}
+
+	if calls != 0 {
+		t.Fatalf("made %d requests with invalid input", calls)
+	}
+}
+
+func TestNormalizedRequest(t *testing.T) {
+	t.Parallel()
+	cat, err := inv.Categorization("HTTPS://WWW.Amazon.com./", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cat.Status != StatusBenign {
+		t.Fatalf("unexpected categorization %v", cat)
+	}
+}
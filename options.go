@@ -0,0 +1,176 @@
+package goinvestigate
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// An Option configures an Investigate client. Options are given to New.
+type Option func(*Investigate)
+
+// Send requests to the given base URL instead of
+// https://investigate.api.opendns.com, e.g. a staging endpoint or a local
+// test server.
+func WithBaseURL(u string) Option {
+	return func(inv *Investigate) {
+		inv.baseUrl = strings.TrimSuffix(u, "/")
+	}
+}
+
+// Make requests with the given HTTP client. The client is used as-is;
+// options which change the client, such as WithTransport and WithTimeout,
+// work on a copy of it. A nil client means a new, default one.
+func WithHTTPClient(client *http.Client) Option {
+	return func(inv *Investigate) {
+		if client == nil {
+			client = &http.Client{}
+		}
+		inv.client = client
+	}
+}
+
+// Make requests through the given RoundTripper, e.g. to go through a proxy.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(inv *Investigate) {
+		client := *inv.client
+		client.Transport = rt
+		inv.client = &client
+	}
+}
+
+// Set a time limit for each HTTP request made by the client, including
+// reading the response body. A zero timeout means no time limit.
+func WithTimeout(timeout time.Duration) Option {
+	return func(inv *Investigate) {
+		client := *inv.client
+		client.Timeout = timeout
+		inv.client = &client
+	}
+}
+
+// Send the given User-Agent header with every request.
+func WithUserAgent(ua string) Option {
+	return func(inv *Investigate) {
+		inv.userAgent = ua
+	}
+}
+
+// Write log messages to the given logger instead of stdout. A nil logger
+// means the default one, which writes to stdout.
+func WithLogger(logger *log.Logger) Option {
+	return func(inv *Investigate) {
+		if logger == nil {
+			logger = newDefaultLogger()
+		}
+		inv.log = logger
+	}
+}
+
+// Sets verbose messages to the given boolean value. Equivalent to
+// calling SetVerbose on the new client.
+func WithVerbose(verbose bool) Option {
+	return func(inv *Investigate) {
+		inv.verbose = verbose
+	}
+}
+
+// Decide whether and when to retry failed requests with the given policy
+// instead of DefaultRetryPolicy(). A nil policy means DefaultRetryPolicy().
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(inv *Investigate) {
+		if policy == nil {
+			policy = DefaultRetryPolicy()
+		}
+		inv.retry = policy
+	}
+}
+
+// Call hook before every retry, e.g. to record metrics.
+func WithRetryHook(hook func(RetryEvent)) Option {
+	return func(inv *Investigate) {
+		inv.retryHook = hook
+	}
+}
+
+// Limit the client to perSecond requests per second on average, with bursts
+// of up to burst requests. A rate which isn't positive leaves the client
+// unlimited.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(inv *Investigate) {
+		inv.limiter = NewRateLimiter(perSecond, burst)
+	}
+}
+
+// Limit the client's requests with the given RateLimiter, which may be
+// shared with other clients.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(inv *Investigate) {
+		inv.limiter = limiter
+	}
+}
+
+// Count the client's requests against the given Quota, which may be shared
+// with other clients.
+func WithQuota(quota *Quota) Option {
+	return func(inv *Investigate) {
+		inv.quota = quota
+	}
+}
+
+// Cache responses in the given Cache. Responses are kept for the TTL of the
+// endpoint they came from; see WithCacheTTL.
+func WithCache(cache Cache) Option {
+	return func(inv *Investigate) {
+		inv.cache = cache
+	}
+}
+
+// Cache responses from the named endpoint, e.g. "categorization" or
+// "domain", for ttl instead of its default TTL. A TTL of zero turns off
+// caching for the endpoint.
+func WithCacheTTL(endpoint string, ttl time.Duration) Option {
+	return func(inv *Investigate) {
+		if inv.cacheTTLs == nil {
+			inv.cacheTTLs = make(map[string]time.Duration)
+		}
+		inv.cacheTTLs[endpoint] = ttl
+	}
+}
+
+// Make up to n requests at once in bulk methods such as SecurityBulk.
+// The default is 10.
+func WithConcurrency(n int) Option {
+	return func(inv *Investigate) {
+		if n > 0 {
+			inv.concurrency = n
+		}
+	}
+}
+
+// Send up to n domains per request in Categorizations. Longer lists are
+// split into several requests. The default is 1000.
+func WithCategorizationBatchSize(n int) Option {
+	return func(inv *Investigate) {
+		if n > 0 {
+			inv.catBatchSize = n
+		}
+	}
+}
+
+// Keep the raw JSON of each response in the Extra field of the value
+// returned, or of each of its elements for lists and maps.
+func WithRawResponses() Option {
+	return func(inv *Investigate) {
+		inv.keepRaw = true
+	}
+}
+
+// Set how response fields which the response types don't know about are
+// treated. The default is DecodeLenient.
+func WithDecodeMode(mode DecodeMode) Option {
+	return func(inv *Investigate) {
+		inv.decodeMode = mode
+	}
+}
@@ -0,0 +1,116 @@
+package goinvestigate
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dead10ck/goinvestigate/goinvestigatetest"
+)
+
+func TestOptions(t *testing.T) {
+	t.Parallel()
+	var gotPath, gotAuth, gotUA string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotUA = r.Header.Get("User-Agent")
+		w.Write([]byte(`[{"name": "bad.example.com", "id": 1}]`))
+	}))
+	defer ts.Close()
+
+	var logBuf bytes.Buffer
+	optInv := New("test_key",
+		WithBaseURL(ts.URL+"/"),
+		WithUserAgent("goinvestigate-test"),
+		WithLogger(log.New(&logBuf, "", 0)),
+		WithVerbose(true),
+	)
+
+	out, err := optInv.LatestDomains("8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 || out[0] != "bad.example.com" {
+		t.Fatalf("%v should be [bad.example.com]", out)
+	}
+
+	if gotPath != "/ips/8.8.8.8/latest_domains" {
+		t.Fatalf("requested path %s", gotPath)
+	}
+
+	if gotAuth != "Bearer test_key" {
+		t.Fatalf("Authorization header was %q", gotAuth)
+	}
+
+	if gotUA != "goinvestigate-test" {
+		t.Fatalf("User-Agent header was %q", gotUA)
+	}
+
+	if logBuf.Len() == 0 {
+		t.Fatal("nothing was written to the logger")
+	}
+}
+
+func TestOptionsCopyHTTPClient(t *testing.T) {
+	t.Parallel()
+	client := &http.Client{}
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, nil
+	})
+	optInv := New("test_key", WithHTTPClient(client), WithTransport(rt), WithTimeout(time.Second))
+
+	if client.Transport != nil || client.Timeout != 0 {
+		t.Fatal("the given http.Client should not be modified")
+	}
+
+	if optInv.client.Transport == nil || optInv.client.Timeout != time.Second {
+		t.Fatalf("options were not applied: %+v", optInv.client)
+	}
+}
+
+func TestOptionsNilHTTPClient(t *testing.T) {
+	t.Parallel()
+	optInv := New("test_key", WithHTTPClient(nil), WithTimeout(time.Second))
+
+	if optInv.client == nil || optInv.client.Timeout != time.Second {
+		t.Fatalf("a nil client should be replaced: %+v", optInv.client)
+	}
+}
+
+func TestOptionsNilRetryPolicy(t *testing.T) {
+	t.Parallel()
+	optInv, srv := newTestClient(t,
+		WithLogger(log.New(ioutil.Discard, "", 0)),
+		WithRetryPolicy(nil),
+	)
+	srv.Inject("/security/", goinvestigatetest.ErrorFault(http.StatusNotFound, 1))
+
+	if _, err := optInv.Security("www.test.com"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("%v should be %v", err, ErrNotFound)
+	}
+}
+
+func TestOptionsNilLogger(t *testing.T) {
+	t.Parallel()
+	optInv, srv := newTestClient(t,
+		WithLogger(nil),
+		WithRetryPolicy(&ExponentialBackoff{MaxAttempts: 2}),
+	)
+	srv.Inject("/security/", goinvestigatetest.ErrorFault(http.StatusBadGateway, 1))
+
+	if optInv.log == nil {
+		t.Fatal("a nil logger should be replaced")
+	}
+
+	// the failed first attempt is logged before it is retried
+	if _, err := optInv.Security("www.test.com"); err != nil {
+		t.Fatal(err)
+	}
+}
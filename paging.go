@@ -0,0 +1,78 @@
+package goinvestigate
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// A Page selects part of the results of an endpoint which returns them a
+// page at a time. A zero Limit leaves the page size up to the API.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// the page size the iterators ask for
+const defaultPageSize = 100
+
+// Adds the page's limit and offset to v, leaving out zero values.
+func (p Page) encode(v url.Values) {
+	if p.Limit > 0 {
+		v.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset > 0 {
+		v.Set("offset", strconv.Itoa(p.Offset))
+	}
+}
+
+// Returns path with the given query parameters, if there are any.
+func withQuery(path string, v url.Values) string {
+	if len(v) == 0 {
+		return path
+	}
+	return path + "?" + v.Encode()
+}
+
+// Iterates over every item of a paged endpoint, fetching each page of
+// pageSize items once the previous one has been consumed. fetch returns the
+// items of a page, and whether there are more after it; if it can't tell,
+// a full page is taken to mean there might be. Iteration ends after the
+// first error, which is yielded with a zero item.
+func paginate[T any](ctx context.Context, pageSize int, fetch func(ctx context.Context, page Page) ([]T, bool, error)) iter.Seq2[T, error] {
+	return paginateBy(ctx, Page{Limit: pageSize}, func(ctx context.Context, page Page) ([]T, Page, bool, error) {
+		items, more, err := fetch(ctx, page)
+		page.Offset += len(items)
+		return items, page, more, err
+	})
+}
+
+// Like paginate, but for endpoints which page by some other cursor than an
+// offset, e.g. the last item of the previous page. fetch returns the items
+// of the page at cursor, the cursor of the next page, and whether there
+// might be more. Iteration also ends on an empty page, or when the cursor
+// doesn't move.
+func paginateBy[T any, C comparable](ctx context.Context, cursor C, fetch func(ctx context.Context, cursor C) ([]T, C, bool, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			items, next, more, err := fetch(ctx, cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if !more || len(items) == 0 || next == cursor {
+				return
+			}
+			cursor = next
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package goinvestigate
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The order passive DNS records are sorted in.
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// Filters and paging for the passive DNS queries. Zero values leave the
+// API's defaults in place.
+type PDNSOptions struct {
+	Page
+	// Only return records of these types
+	RecordTypes []QueryType
+	// Only return records seen within this window
+	Start time.Time
+	Stop  time.Time
+	// The field to sort by, e.g. "firstSeen" or "lastSeen"
+	SortBy    string
+	SortOrder SortOrder
+}
+
+// Adds the options to v, checking the record types.
+func (o PDNSOptions) encode(v url.Values) error {
+	o.Page.encode(v)
+
+	if len(o.RecordTypes) > 0 {
+		types := make([]string, len(o.RecordTypes))
+		for i, qType := range o.RecordTypes {
+			if !queryTypeSupported(qType) {
+				return fmt.Errorf("%w: %s", ErrUnsupportedQueryType, qType)
+			}
+			types[i] = string(qType)
+		}
+		v.Set("recordType", strings.Join(types, ","))
+	}
+
+	if !o.Start.IsZero() {
+		v.Set("start", strconv.FormatInt(o.Start.UnixMilli(), 10))
+	}
+	if !o.Stop.IsZero() {
+		v.Set("stop", strconv.FormatInt(o.Stop.UnixMilli(), 10))
+	}
+	if o.SortBy != "" {
+		v.Set("sortby", o.SortBy)
+	}
+	if o.SortOrder != "" {
+		v.Set("sortorder", string(o.SortOrder))
+	}
+	return nil
+}
+
+// A record from the passive DNS database.
+type PDNSRecord struct {
+	Name               string
+	Type               QueryType
+	RR                 string
+	MinTTL             int        `json:"minTtl"`
+	MaxTTL             int        `json:"maxTtl"`
+	FirstSeen          Timestamp  `json:"firstSeenISO"`
+	LastSeen           Timestamp  `json:"lastSeenISO"`
+	FirstSeenUnix      int64      `json:"firstSeen"`
+	LastSeenUnix       int64      `json:"lastSeen"`
+	SecurityCategories []string   `json:"securityCategories"`
+	ContentCategories  []string   `json:"contentCategories"`
+	Extra              *RawFields `json:"-"`
+}
+
+func (r *PDNSRecord) UnmarshalJSON(b []byte) error {
+	type alias PDNSRecord
+	return decodeFields(b, (*alias)(r), &r.Extra)
+}
+
+// The record's name and value as a ResourceRecord, for its typed accessors.
+func (r PDNSRecord) ResourceRecord() ResourceRecord {
+	return ResourceRecord{
+		Name:  r.Name,
+		TTL:   r.MaxTTL,
+		Class: "IN",
+		Type:  string(r.Type),
+		RR:    r.RR,
+	}
+}
+
+// Where a page of passive DNS records is within all of them.
+type PDNSPageInfo struct {
+	HasMoreRecords  bool `json:"hasMoreRecords"`
+	Offset          int
+	Limit           int
+	TotalNumRecords int        `json:"totalNumRecords"`
+	Extra           *RawFields `json:"-"`
+}
+
+func (p *PDNSPageInfo) UnmarshalJSON(b []byte) error {
+	type alias PDNSPageInfo
+	return decodeFields(b, (*alias)(p), &p.Extra)
+}
+
+// A page of passive DNS records.
+type PDNSResult struct {
+	PageInfo PDNSPageInfo `json:"pageInfo"`
+	Records  []PDNSRecord
+	Extra    *RawFields `json:"-"`
+}
+
+func (r *PDNSResult) UnmarshalJSON(b []byte) error {
+	type alias PDNSResult
+	return decodeFields(b, (*alias)(r), &r.Extra)
+}
+
+// How a domain was classified at a point in time.
+type PDNSTimelineEntry struct {
+	Categories  []string
+	Attacks     []string
+	ThreatTypes []string `json:"threatTypes"`
+	// When the classification changed, in milliseconds since the epoch
+	Timestamp int64
+	Extra     *RawFields `json:"-"`
+}
+
+func (e *PDNSTimelineEntry) UnmarshalJSON(b []byte) error {
+	type alias PDNSTimelineEntry
+	return decodeFields(b, (*alias)(e), &e.Extra)
+}
+
+// When the classification changed.
+func (e PDNSTimelineEntry) Time() time.Time {
+	return time.UnixMilli(e.Timestamp).UTC()
+}
+
+// Fetches a page of one of the passive DNS endpoints.
+func (inv *Investigate) pdns(ctx context.Context, endpoint string, segment string, opts PDNSOptions) (*PDNSResult, error) {
+	v := url.Values{}
+	if err := opts.encode(v); err != nil {
+		return nil, err
+	}
+	resp := new(PDNSResult)
+	err := inv.GetParseContext(ctx, withQuery(fmt.Sprintf(urls[endpoint], segment), v), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Iterates over the records of one of the passive DNS endpoints, starting
+// at the options' offset.
+func (inv *Investigate) pdnsAll(ctx context.Context, fetch func(context.Context, PDNSOptions) (*PDNSResult, error), opts PDNSOptions) iter.Seq2[PDNSRecord, error] {
+	pageSize := opts.Limit
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	start := opts.Offset
+	return paginate(ctx, pageSize, func(ctx context.Context, page Page) ([]PDNSRecord, bool, error) {
+		opts.Page = Page{Limit: page.Limit, Offset: start + page.Offset}
+		resp, err := fetch(ctx, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Records, resp.PageInfo.HasMoreRecords, nil
+	})
+}
+
+// Get a page of the passive DNS records of the given domain and its
+// subdomains.
+//
+// For details, see https://sgraph.opendns.com/docs/api#pdns
+func (inv *Investigate) PDNSDomain(domain string, opts PDNSOptions) (*PDNSResult, error) {
+	return inv.PDNSDomainContext(context.Background(), domain, opts)
+}
+
+// Like PDNSDomain, but the request is bound to ctx.
+func (inv *Investigate) PDNSDomainContext(ctx context.Context, domain string, opts PDNSOptions) (*PDNSResult, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
+	return inv.pdns(ctx, "pdns_domain", segment, opts)
+}
+
+// Iterate over every passive DNS record of the given domain and its
+// subdomains, fetching more pages as they are needed.
+func (inv *Investigate) PDNSDomainAll(ctx context.Context, domain string, opts PDNSOptions) iter.Seq2[PDNSRecord, error] {
+	return inv.pdnsAll(ctx, func(ctx context.Context, opts PDNSOptions) (*PDNSResult, error) {
+		return inv.PDNSDomainContext(ctx, domain, opts)
+	}, opts)
+}
+
+// Get a page of the passive DNS records of exactly the given name.
+//
+// For details, see https://sgraph.opendns.com/docs/api#pdns
+func (inv *Investigate) PDNSName(name string, opts PDNSOptions) (*PDNSResult, error) {
+	return inv.PDNSNameContext(context.Background(), name, opts)
+}
+
+// Like PDNSName, but the request is bound to ctx.
+func (inv *Investigate) PDNSNameContext(ctx context.Context, name string, opts PDNSOptions) (*PDNSResult, error) {
+	segment, err := domainSegment(name)
+	if err != nil {
+		return nil, err
+	}
+	return inv.pdns(ctx, "pdns_name", segment, opts)
+}
+
+// Iterate over every passive DNS record of exactly the given name,
+// fetching more pages as they are needed.
+func (inv *Investigate) PDNSNameAll(ctx context.Context, name string, opts PDNSOptions) iter.Seq2[PDNSRecord, error] {
+	return inv.pdnsAll(ctx, func(ctx context.Context, opts PDNSOptions) (*PDNSResult, error) {
+		return inv.PDNSNameContext(ctx, name, opts)
+	}, opts)
+}
+
+// Get a page of the passive DNS records which point to the given IP.
+//
+// For details, see https://sgraph.opendns.com/docs/api#pdns
+func (inv *Investigate) PDNSIP(ip string, opts PDNSOptions) (*PDNSResult, error) {
+	return inv.PDNSIPContext(context.Background(), ip, opts)
+}
+
+// Like PDNSIP, but the request is bound to ctx.
+func (inv *Investigate) PDNSIPContext(ctx context.Context, ip string, opts PDNSOptions) (*PDNSResult, error) {
+	segment, err := ipSegment(ip)
+	if err != nil {
+		return nil, err
+	}
+	return inv.pdns(ctx, "pdns_ip", segment, opts)
+}
+
+// Iterate over every passive DNS record which points to the given IP,
+// fetching more pages as they are needed.
+func (inv *Investigate) PDNSIPAll(ctx context.Context, ip string, opts PDNSOptions) iter.Seq2[PDNSRecord, error] {
+	return inv.pdnsAll(ctx, func(ctx context.Context, opts PDNSOptions) (*PDNSResult, error) {
+		return inv.PDNSIPContext(ctx, ip, opts)
+	}, opts)
+}
+
+// Get a page of the passive DNS records whose value contains the given
+// string, e.g. part of a TXT record.
+//
+// For details, see https://sgraph.opendns.com/docs/api#pdns
+func (inv *Investigate) PDNSRaw(query string, opts PDNSOptions) (*PDNSResult, error) {
+	return inv.PDNSRawContext(context.Background(), query, opts)
+}
+
+// Like PDNSRaw, but the request is bound to ctx.
+func (inv *Investigate) PDNSRawContext(ctx context.Context, query string, opts PDNSOptions) (*PDNSResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, &ValidationError{Kind: "query", Input: query, Reason: "empty"}
+	}
+	return inv.pdns(ctx, "pdns_raw", url.PathEscape(query), opts)
+}
+
+// Iterate over every passive DNS record whose value contains the given
+// string, fetching more pages as they are needed.
+func (inv *Investigate) PDNSRawAll(ctx context.Context, query string, opts PDNSOptions) iter.Seq2[PDNSRecord, error] {
+	return inv.pdnsAll(ctx, func(ctx context.Context, opts PDNSOptions) (*PDNSResult, error) {
+		return inv.PDNSRawContext(ctx, query, opts)
+	}, opts)
+}
+
+// Get the history of how the given domain has been classified.
+//
+// For details, see https://sgraph.opendns.com/docs/api#timeline
+func (inv *Investigate) PDNSTimeline(domain string) ([]PDNSTimelineEntry, error) {
+	return inv.PDNSTimelineContext(context.Background(), domain)
+}
+
+// Like PDNSTimeline, but the request is bound to ctx.
+func (inv *Investigate) PDNSTimelineContext(ctx context.Context, domain string) ([]PDNSTimelineEntry, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
+	var resp []PDNSTimelineEntry
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["pdns_timeline"], segment), &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,88 @@
+package goinvestigate
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPDNSOptions(t *testing.T) {
+	t.Parallel()
+	v := url.Values{}
+	opts := PDNSOptions{
+		Page:        Page{Limit: 10, Offset: 20},
+		RecordTypes: []QueryType{QueryA, QueryAAAA},
+		Start:       time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		SortBy:      "lastSeen",
+		SortOrder:   SortDescending,
+	}
+	if err := opts.encode(v); err != nil {
+		t.Fatal(err)
+	}
+
+	ref := "limit=10&offset=20&recordType=A%2CAAAA&sortby=lastSeen&sortorder=desc&start=1577836800000"
+	if v.Encode() != ref {
+		t.Fatalf("%s should be %s", v.Encode(), ref)
+	}
+
+	opts.RecordTypes = []QueryType{"BOGUS"}
+	if err := opts.encode(url.Values{}); !errors.Is(err, ErrUnsupportedQueryType) {
+		t.Fatalf("%v should be %v", err, ErrUnsupportedQueryType)
+	}
+}
+
+func TestPDNS(t *testing.T) {
+	t.Parallel()
+	pdnsInv, srv := newTestClient(t, WithDecodeMode(DecodeStrict))
+
+	out, err := pdnsInv.PDNSDomain("example.com", PDNSOptions{RecordTypes: []QueryType{QueryA, QueryMX}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.Records) != 3 || out.PageInfo.TotalNumRecords != 3 || out.PageInfo.HasMoreRecords {
+		t.Fatalf("unexpected result %+v", out)
+	}
+
+	first := out.Records[0]
+	if !first.FirstSeen.Time.Equal(time.Unix(first.FirstSeenUnix, 0)) || first.LastSeen.Time.Sub(first.FirstSeen.Time) != 24*time.Hour {
+		t.Fatalf("unexpected first and last seen %v, %v", first.FirstSeen, first.LastSeen)
+	}
+
+	if addr, err := first.ResourceRecord().Addr(); err != nil || addr.String() != "93.184.216.34" {
+		t.Fatalf("got %v, %v", addr, err)
+	}
+
+	var types []string
+	for record, err := range pdnsInv.PDNSIPAll(context.Background(), "93.184.216.34", PDNSOptions{Page: Page{Limit: 2}}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, string(record.Type))
+	}
+
+	if strings.Join(types, ",") != "A,A,AAAA,MX,TXT" {
+		t.Fatalf("unexpected records %v", types)
+	}
+
+	// PDNSDomain, then 3 pages of 2
+	if requests := len(srv.Requests()); requests != 4 {
+		t.Fatalf("made %d requests", requests)
+	}
+
+	timeline, err := pdnsInv.PDNSTimeline("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(timeline) != 2 || timeline[1].Attacks[0] != "Neutrino" || timeline[1].Time().Month() != time.February {
+		t.Fatalf("unexpected timeline %+v", timeline)
+	}
+
+	if _, err := pdnsInv.PDNSRaw(" ", PDNSOptions{}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("%v should be %v", err, ErrInvalidInput)
+	}
+}
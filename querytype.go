@@ -0,0 +1,145 @@
+package goinvestigate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// The type of DNS query to look up the RR History of, e.g. QueryA.
+type QueryType string
+
+const (
+	QueryA      QueryType = "A"
+	QueryAAAA   QueryType = "AAAA"
+	QueryCNAME  QueryType = "CNAME"
+	QueryMX     QueryType = "MX"
+	QueryNS     QueryType = "NS"
+	QueryPTR    QueryType = "PTR"
+	QuerySOA    QueryType = "SOA"
+	QuerySRV    QueryType = "SRV"
+	QueryTXT    QueryType = "TXT"
+	QuerySPF    QueryType = "SPF"
+	QueryCAA    QueryType = "CAA"
+	QueryNAPTR  QueryType = "NAPTR"
+	QueryDS     QueryType = "DS"
+	QueryDNSKEY QueryType = "DNSKEY"
+	QueryHINFO  QueryType = "HINFO"
+)
+
+// the query types the passive DNS database keeps, in the order
+// SupportedQueryTypes gives them
+var supportedQueryTypes = []QueryType{
+	QueryA,
+	QueryAAAA,
+	QueryCNAME,
+	QueryMX,
+	QueryNS,
+	QueryPTR,
+	QuerySOA,
+	QuerySRV,
+	QueryTXT,
+	QuerySPF,
+	QueryCAA,
+	QueryNAPTR,
+	QueryDS,
+	QueryDNSKEY,
+	QueryHINFO,
+}
+
+// The query types which can be given to DomainRRHistory and IpRRHistory.
+func SupportedQueryTypes() []QueryType {
+	return slices.Clone(supportedQueryTypes)
+}
+
+func queryTypeSupported(qType QueryType) bool {
+	return slices.Contains(supportedQueryTypes, qType)
+}
+
+// Get the RR (Resource Record) History of the given domain for every
+// supported query type, making the requests concurrently, and merge them
+// into one history. The periods of all the query types are sorted by when
+// they were first seen, and the features are those of the A records.
+//
+// Query types the domain has no records for are left out. If some of the
+// requests fail, the merged history of the others is returned along with an
+// error for each failed query type.
+//
+// For details, see https://sgraph.opendns.com/docs/api#dnsrr_domain
+func (inv *Investigate) DomainRRHistoryAllTypes(domain string) (*DomainRRHistory, error) {
+	return inv.DomainRRHistoryAllTypesContext(context.Background(), domain)
+}
+
+// Like DomainRRHistoryAllTypes, but the requests are bound to ctx.
+func (inv *Investigate) DomainRRHistoryAllTypesContext(ctx context.Context, domain string) (*DomainRRHistory, error) {
+	// fail once on an invalid domain, rather than once per query type
+	domain, err := NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	histories := make([]*DomainRRHistory, len(supportedQueryTypes))
+	errs := make([]error, len(supportedQueryTypes))
+	sem := make(chan struct{}, inv.concurrency)
+	var wg sync.WaitGroup
+	for i, qType := range supportedQueryTypes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, qType QueryType) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			histories[i], errs[i] = inv.DomainRRHistoryContext(ctx, domain, qType)
+		}(i, qType)
+	}
+	wg.Wait()
+
+	return MergeRRHistories(supportedQueryTypes, histories, errs)
+}
+
+// Merge the RR histories of a domain for several query types into one, as
+// DomainRRHistoryAllTypes does. histories and errs hold the outcome of the
+// lookup for each of types, in the same order.
+//
+// The periods are sorted by when they were first seen, and the features are
+// those of the A records. Query types whose lookup failed with ErrNotFound
+// are left out, as are nil histories. The other failures are returned as an
+// error for each query type, along with the merged history, unless every
+// lookup failed. types, histories and errs must be of the same length.
+func MergeRRHistories(types []QueryType, histories []*DomainRRHistory, errs []error) (*DomainRRHistory, error) {
+	if len(histories) != len(types) || len(errs) != len(types) {
+		return nil, fmt.Errorf("merging RR histories: %d query types, but %d histories and %d errors",
+			len(types), len(histories), len(errs))
+	}
+
+	merged := new(DomainRRHistory)
+	var failures []error
+	succeeded := 0
+	for i, history := range histories {
+		switch {
+		case errors.Is(errs[i], ErrNotFound), errs[i] == nil && history == nil:
+			succeeded++
+			continue
+		case errs[i] != nil:
+			failures = append(failures, fmt.Errorf("%s: %w", types[i], errs[i]))
+			continue
+		}
+
+		succeeded++
+		merged.RRPeriods = append(merged.RRPeriods, history.RRPeriods...)
+		if types[i] == QueryA {
+			merged.RRFeatures = history.RRFeatures
+		}
+	}
+
+	if succeeded == 0 {
+		return nil, errors.Join(failures...)
+	}
+
+	sort.SliceStable(merged.RRPeriods, func(i, j int) bool {
+		return merged.RRPeriods[i].FirstSeen.Time.Before(merged.RRPeriods[j].FirstSeen.Time)
+	})
+	return merged, errors.Join(failures...)
+}
@@ -0,0 +1,137 @@
+package goinvestigate
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dead10ck/goinvestigate/goinvestigatetest"
+)
+
+func TestUnsupportedQueryType(t *testing.T) {
+	t.Parallel()
+	if _, err := inv.DomainRRHistory("www.test.com", "BOGUS"); !errors.Is(err, ErrUnsupportedQueryType) {
+		t.Fatalf("%v should be %v", err, ErrUnsupportedQueryType)
+	}
+
+	if _, err := inv.IpRRHistory("208.64.121.161", QueryType("a")); !errors.Is(err, ErrUnsupportedQueryType) {
+		t.Fatalf("%v should be %v", err, ErrUnsupportedQueryType)
+	}
+
+	types := SupportedQueryTypes()
+	types[0] = "BOGUS"
+	if SupportedQueryTypes()[0] != QueryA {
+		t.Fatal("SupportedQueryTypes returned its own slice")
+	}
+}
+
+func TestDomainRRHistoryAllTypes(t *testing.T) {
+	t.Parallel()
+	allInv, srv := newTestClient(t, WithRetryPolicy(&ExponentialBackoff{MaxAttempts: 1}))
+
+	srv.SetResponse("/dnsdb/name/MX/bibikun.ru.json", `{"rrs_tf": [{"first_seen": "2012-01-01", "last_seen": "2012-02-01", "rrs": [
+		{"name": "bibikun.ru.", "ttl": 3600, "class": "IN", "type": "MX", "rr": "10 mx.bibikun.ru."}]}], "features": {}}`)
+	srv.Inject("/dnsdb/name/TXT/", goinvestigatetest.ErrorFault(http.StatusNotFound, 1))
+	srv.Inject("/dnsdb/name/SOA/", goinvestigatetest.ErrorFault(http.StatusInternalServerError, 1))
+
+	out, err := allInv.DomainRRHistoryAllTypes("bibikun.ru")
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("%v should only report the SOA failure", err)
+	}
+
+	// every type but TXT and SOA
+	if len(out.RRPeriods) != len(supportedQueryTypes)-2 {
+		t.Fatalf("got %d periods", len(out.RRPeriods))
+	}
+
+	first := out.RRPeriods[0]
+	if first.RRs[0].Type != "MX" {
+		t.Fatalf("periods weren't sorted: %v", first)
+	}
+
+	if out.RRFeatures.Age != 91 {
+		t.Fatalf("features should be those of the A records: %+v", out.RRFeatures)
+	}
+}
+
+func TestMergeRRHistories(t *testing.T) {
+	t.Parallel()
+	aHistory := &DomainRRHistory{
+		RRPeriods:  []ResourceRecordPeriod{{FirstSeen: mustTimestamp(t, "2014-01-01")}},
+		RRFeatures: DomainResourceRecordFeatures{Age: 3},
+	}
+	mxHistory := &DomainRRHistory{RRPeriods: []ResourceRecordPeriod{{FirstSeen: mustTimestamp(t, "2013-01-01")}}}
+
+	cases := []struct {
+		name      string
+		types     []QueryType
+		histories []*DomainRRHistory
+		errs      []error
+		// the first seen dates of the merged periods, or nil for no history
+		periods []string
+		err     error
+		failed  bool
+	}{
+		{
+			name:      "partial failure",
+			types:     []QueryType{QueryA, QueryMX, QueryTXT},
+			histories: []*DomainRRHistory{aHistory, mxHistory, nil},
+			errs:      []error{nil, nil, ErrRateLimited},
+			periods:   []string{"2013-01-01", "2014-01-01"},
+			err:       ErrRateLimited,
+		},
+		{
+			name:      "every lookup failed",
+			types:     []QueryType{QueryTXT},
+			histories: []*DomainRRHistory{nil},
+			errs:      []error{ErrRateLimited},
+			err:       ErrRateLimited,
+			failed:    true,
+		},
+		{
+			name:      "nil history",
+			types:     []QueryType{QueryA, QueryMX},
+			histories: []*DomainRRHistory{aHistory, nil},
+			errs:      []error{nil, nil},
+			periods:   []string{"2014-01-01"},
+		},
+		{
+			name:      "short errors",
+			types:     []QueryType{QueryA, QueryMX},
+			histories: []*DomainRRHistory{aHistory, mxHistory},
+			errs:      []error{nil},
+			failed:    true,
+		},
+		{
+			name:      "short histories",
+			types:     []QueryType{QueryA, QueryMX},
+			histories: []*DomainRRHistory{aHistory},
+			errs:      []error{nil, nil},
+			failed:    true,
+		},
+	}
+
+	for _, c := range cases {
+		merged, err := MergeRRHistories(c.types, c.histories, c.errs)
+		if c.failed {
+			if merged != nil || err == nil {
+				t.Fatalf("%s: got %+v, %v; should fail", c.name, merged, err)
+			}
+			continue
+		}
+
+		if c.err != nil && !errors.Is(err, c.err) || c.err == nil && err != nil {
+			t.Fatalf("%s: %v should be %v", c.name, err, c.err)
+		}
+
+		if merged == nil || len(merged.RRPeriods) != len(c.periods) || merged.RRFeatures.Age != 3 {
+			t.Fatalf("%s: unexpected merged history %+v", c.name, merged)
+		}
+
+		for i, ref := range c.periods {
+			if merged.RRPeriods[i].FirstSeen.Raw != ref {
+				t.Fatalf("%s: period %d was first seen %s, should be %s", c.name, i, merged.RRPeriods[i].FirstSeen.Raw, ref)
+			}
+		}
+	}
+}
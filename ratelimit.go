@@ -0,0 +1,265 @@
+package goinvestigate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Returned, wrapped in a *QuotaError, once a Quota's daily budget is used up.
+var ErrQuotaExceeded = errors.New("daily quota exceeded")
+
+// A RateLimiter is a token bucket which limits how many requests are made per
+// second. It is safe for concurrent use, and can be shared between several
+// clients which use the same API key.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// Build a RateLimiter which allows perSecond requests per second on average,
+// with bursts of up to burst requests. A rate which isn't positive doesn't
+// limit requests at all.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be made, or until ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Takes a token if one is available. Otherwise, returns how long it will be
+// until one is.
+func (l *RateLimiter) reserve() time.Duration {
+	// unlimited, rather than never refilling
+	if !(l.rate > 0) {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// A QuotaError is returned instead of making a request once a Quota's
+// budget is used up. It matches ErrQuotaExceeded with errors.Is.
+type QuotaError struct {
+	Endpoint string
+	Budget   int
+	// When the budget will be replenished
+	Reset time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %v: budget of %d requests resets at %v",
+		e.Endpoint, ErrQuotaExceeded, e.Budget, e.Reset.Format(time.RFC3339))
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// A Quota counts the requests made to each endpoint during the current UTC
+// day, and refuses further requests once its daily budget is used up.
+// Endpoints are identified by their names in the client's URL table, e.g.
+// "security" or "categorization". Retries count against the quota, since
+// the API counts them too.
+//
+// It is safe for concurrent use, and can be shared between several clients.
+type Quota struct {
+	mu     sync.Mutex
+	budget int
+	day    time.Time
+	total  int
+	counts map[string]int
+}
+
+// Build a Quota which allows dailyBudget requests per day in total.
+// A budget of 0 means no limit; requests are only counted.
+func NewQuota(dailyBudget int) *Quota {
+	return &Quota{
+		budget: dailyBudget,
+		day:    today(),
+		counts: make(map[string]int),
+	}
+}
+
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// Starts a new day if it's time to.
+func (q *Quota) roll() {
+	if day := today(); day.After(q.day) {
+		q.day = day
+		q.total = 0
+		q.counts = make(map[string]int)
+	}
+}
+
+// Counts a request to endpoint, unless the budget is used up.
+func (q *Quota) take(endpoint string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+
+	if q.budget > 0 && q.total >= q.budget {
+		return &QuotaError{
+			Endpoint: endpoint,
+			Budget:   q.budget,
+			Reset:    q.day.Add(24 * time.Hour),
+		}
+	}
+
+	q.total++
+	q.counts[endpoint]++
+	return nil
+}
+
+// Counts returns the number of requests made today to each endpoint.
+func (q *Quota) Counts() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+
+	counts := make(map[string]int, len(q.counts))
+	for endpoint, n := range q.counts {
+		counts[endpoint] = n
+	}
+	return counts
+}
+
+// Total returns the number of requests made today.
+func (q *Quota) Total() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+	return q.total
+}
+
+// Remaining returns the number of requests left in today's budget, or -1 if
+// the quota has no budget.
+func (q *Quota) Remaining() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+
+	if q.budget <= 0 {
+		return -1
+	}
+	if q.total >= q.budget {
+		return 0
+	}
+	return q.budget - q.total
+}
+
+// The pattern matching the paths of an endpoint in urls.
+type endpointPattern struct {
+	name    string
+	pattern *regexp.Regexp
+	// whether each segment of the path is fixed, rather than filled in
+	fixed []bool
+}
+
+// patterns matching the paths of each endpoint in urls, most specific first
+var endpointPatterns = buildEndpointPatterns()
+
+// Builds the patterns in a fixed order, so that a path which matches several
+// of them is always counted under the same name: going through the segments
+// from the left, the first pattern with a fixed segment where the others
+// fill one in wins, and ties go to the first name.
+func buildEndpointPatterns() []endpointPattern {
+	patterns := make([]endpointPattern, 0, len(urls))
+	for name, format := range urls {
+		parts := strings.Split(format, "%s")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+
+		var fixed []bool
+		for _, segment := range strings.Split(format, "/") {
+			fixed = append(fixed, !strings.Contains(segment, "%s"))
+		}
+
+		patterns = append(patterns, endpointPattern{
+			name:    name,
+			pattern: regexp.MustCompile("^" + strings.Join(parts, "[^/]*") + "$"),
+			fixed:   fixed,
+		})
+	}
+
+	slices.SortFunc(patterns, func(a, b endpointPattern) int {
+		for i := 0; i < len(a.fixed) && i < len(b.fixed); i++ {
+			if a.fixed[i] != b.fixed[i] {
+				if a.fixed[i] {
+					return -1
+				}
+				return 1
+			}
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	return patterns
+}
+
+// The name in urls of the endpoint the given path belongs to, or the path
+// itself if it doesn't belong to any of them.
+func endpointName(path string) string {
+	for _, p := range endpointPatterns {
+		if p.pattern.MatchString(path) {
+			return p.name
+		}
+	}
+	return path
+}
+
+// The name in urls of the endpoint the given request is made to. Any path
+// the base URL has, e.g. of a proxy, is ignored. The path is matched in its
+// escaped form, so that an escaped "/" in a segment stays within it.
+func (inv *Investigate) requestEndpoint(req *http.Request) string {
+	path := req.URL.EscapedPath()
+	if base, err := url.Parse(inv.baseUrl); err == nil {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(base.EscapedPath(), "/"))
+	}
+	return endpointName(path)
+}
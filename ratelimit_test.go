@@ -0,0 +1,171 @@
+package goinvestigate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+	limiter := NewRateLimiter(100, 5)
+	ctx := context.Background()
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 15; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the first 5 are a burst, the other 10 take 10ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("15 requests took %v, should take at least 100ms", elapsed)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	t.Parallel()
+	for _, rate := range []float64{0, -1, math.NaN()} {
+		limiter := NewRateLimiter(rate, 1)
+		for i := 0; i < 3; i++ {
+			if wait := limiter.reserve(); wait != 0 {
+				t.Fatalf("rate %v: should not wait, got %v", rate, wait)
+			}
+		}
+	}
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	t.Parallel()
+	limiter := NewRateLimiter(0.001, 1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("%v should be %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestQuota(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/pdns/") {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	quota := NewQuota(4)
+	quotaInv := New("test_key", WithBaseURL(ts.URL), WithQuota(quota))
+
+	// the escaped "/" mustn't split the query into another path segment
+	if _, err := quotaInv.PDNSRaw("v=spf1 include:_spf.google.com ~all/x", PDNSOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := quotaInv.DomainTags("www.test.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := quotaInv.LatestDomains("8.8.8.8"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := quotaInv.LatestDomains("8.8.8.8")
+	var quotaErr *QuotaError
+	if !errors.Is(err, ErrQuotaExceeded) || !errors.As(err, &quotaErr) || quotaErr.Endpoint != "latest_domains" {
+		t.Fatalf("%v should be a *QuotaError for latest_domains", err)
+	}
+
+	counts := quota.Counts()
+	if counts["tags"] != 2 || counts["latest_domains"] != 1 || counts["pdns_raw"] != 1 ||
+		quota.Total() != 4 || quota.Remaining() != 0 {
+		t.Fatalf("unexpected counts %v, total %d", counts, quota.Total())
+	}
+}
+
+func TestQuotaBaseURLPath(t *testing.T) {
+	t.Parallel()
+	var gotPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	quota := NewQuota(0)
+	quotaInv := New("test_key", WithBaseURL(ts.URL+"/investigate/v2"), WithQuota(quota))
+
+	if _, err := quotaInv.Security("www.test.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotPath != "/investigate/v2/security/name/www.test.com.json" {
+		t.Fatalf("requested path %s", gotPath)
+	}
+
+	if counts := quota.Counts(); len(counts) != 1 || counts["security"] != 1 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+}
+
+func TestQuotaNotTakenWhileRateLimited(t *testing.T) {
+	t.Parallel()
+	limiter := NewRateLimiter(0.001, 1)
+	limiter.Wait(context.Background())
+	quota := NewQuota(3)
+	quotaInv := New("test_key", WithRateLimiter(limiter), WithQuota(quota))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := quotaInv.DomainTagsContext(ctx, "www.test.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("%v should be %v", err, context.DeadlineExceeded)
+	}
+
+	// the request was never sent, so it shouldn't count
+	if n := quota.Remaining(); n != 3 {
+		t.Fatalf("%d of the quota remains, should be 3", n)
+	}
+}
+
+func TestEndpointName(t *testing.T) {
+	t.Parallel()
+	paths := map[string]string{
+		"/dnsdb/ip/a/1.2.3.4.json":         "ip",
+		"/dnsdb/name/MX/www.test.com.json": "domain",
+		"/domains/categorization/":         "categorization",
+		"/domains/categorization/test.com": "categorization",
+		"/domains/test.com/latest_tags":    "tags",
+		"/security/name/test.com.json":     "security",
+		// matched by both, but always counted under the more specific one
+		"/domains/categorization/latest_tags": "categorization",
+		"/whois/emails/history":               "whois_emails",
+		"/not/an/endpoint":                    "/not/an/endpoint",
+	}
+
+	for path, ref := range paths {
+		if name := endpointName(path); name != ref {
+			t.Fatalf("%s: %s should be %s", path, name, ref)
+		}
+	}
+}
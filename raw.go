@@ -0,0 +1,242 @@
+package goinvestigate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Returned, wrapped in a *SchemaDriftError, by clients in DecodeStrict mode
+// when a response has fields which the response types don't know about.
+var ErrSchemaDrift = errors.New("response has unknown fields")
+
+// How a client treats response fields which the response types don't know
+// about. Unknown fields are always kept in the Extra field of the value
+// they belong to.
+type DecodeMode int
+
+const (
+	// Keep unknown fields quietly.
+	DecodeLenient DecodeMode = iota
+	// Log unknown fields, whether or not the client is verbose.
+	DecodeWarn
+	// Fail with a *SchemaDriftError. The typed methods, such as Security,
+	// return no value along with it. Values passed to GetParse and
+	// PostParse are still filled in, and GetJSON and PostJSON still return
+	// the decoded value, along with its Extra fields, so use those to get
+	// at a response despite its unknown fields.
+	DecodeStrict
+)
+
+// RawFields holds the parts of a response which don't map onto the fields of
+// a response type.
+type RawFields struct {
+	// The raw JSON of the value. Only kept for values returned by client
+	// methods, rather than nested within them, and only when the client was
+	// built with WithRawResponses.
+	Raw json.RawMessage
+
+	// The object's fields which the response type has no field for, by name
+	Unknown map[string]json.RawMessage
+}
+
+// A SchemaDriftError lists the unknown fields of a response, by their path
+// within it, e.g. "features.new_score" or "rrs_tf[].rrs[].new_field".
+type SchemaDriftError struct {
+	Fields []string
+}
+
+func (e *SchemaDriftError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrSchemaDrift, strings.Join(e.Fields, ", "))
+}
+
+func (e *SchemaDriftError) Unwrap() error {
+	return ErrSchemaDrift
+}
+
+// the lowercased JSON names of the fields of each response type, which is
+// how encoding/json matches them
+var knownFieldsCache sync.Map
+
+func knownFields(t reflect.Type) map[string]bool {
+	if known, ok := knownFieldsCache.Load(t); ok {
+		return known.(map[string]bool)
+	}
+
+	known := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := jsonName(t.Field(i)); ok {
+			known[strings.ToLower(name)] = true
+		}
+	}
+
+	knownFieldsCache.Store(t, known)
+	return known
+}
+
+// The name of the given field in JSON, and whether it's in the JSON at all.
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return f.Name, true
+}
+
+// Decodes b into v, which must be a pointer to a response type with no
+// UnmarshalJSON method of its own (i.e. an alias of the real type), and
+// stores the object's unknown fields in extra.
+func decodeFields(b []byte, v interface{}, extra **RawFields) error {
+	if err := json.Unmarshal(b, v); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	known := knownFields(reflect.TypeOf(v).Elem())
+	var unknown map[string]json.RawMessage
+	for name, value := range raw {
+		if known[strings.ToLower(name)] {
+			continue
+		}
+		if unknown == nil {
+			unknown = make(map[string]json.RawMessage)
+		}
+		unknown[name] = value
+	}
+
+	*extra = nil
+	if unknown != nil {
+		*extra = &RawFields{Unknown: unknown}
+	}
+	return nil
+}
+
+var rawFieldsType = reflect.TypeOf((*RawFields)(nil))
+
+// The Extra field of the struct v, if it has one.
+func extraField(v reflect.Value) (reflect.Value, bool) {
+	f := v.FieldByName("Extra")
+	return f, f.IsValid() && f.Type() == rawFieldsType
+}
+
+// Keeps the raw JSON of a decoded response in the Extra field of the value
+// v points to. If v is a slice or map, each of its elements gets its own
+// raw JSON instead.
+func attachRaw(v interface{}, body []byte) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	setRaw := func(elem reflect.Value, raw json.RawMessage) {
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				return
+			}
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct || !elem.CanSet() {
+			return
+		}
+		if extra, ok := extraField(elem); ok {
+			if extra.IsNil() {
+				extra.Set(reflect.ValueOf(&RawFields{}))
+			}
+			extra.Interface().(*RawFields).Raw = raw
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		setRaw(rv, append(json.RawMessage(nil), body...))
+	case reflect.Slice:
+		var raws []json.RawMessage
+		if json.Unmarshal(body, &raws) != nil || len(raws) != rv.Len() {
+			return
+		}
+		for i := range raws {
+			setRaw(rv.Index(i), raws[i])
+		}
+	case reflect.Map:
+		var raws map[string]json.RawMessage
+		if json.Unmarshal(body, &raws) != nil || rv.Type().Key().Kind() != reflect.String {
+			return
+		}
+		// map elements can't be modified in place, so update copies
+		for _, key := range rv.MapKeys() {
+			raw, ok := raws[key.String()]
+			if !ok {
+				continue
+			}
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			elem.Set(rv.MapIndex(key))
+			setRaw(elem, raw)
+			rv.SetMapIndex(key, elem)
+		}
+	}
+}
+
+// Lists the unknown fields found anywhere within v, by their path.
+func unknownFields(v interface{}) []string {
+	seen := make(map[string]bool)
+	collectUnknown(reflect.ValueOf(v), "", seen)
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func collectUnknown(v reflect.Value, path string, seen map[string]bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			collectUnknown(v.Elem(), path, seen)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectUnknown(v.Index(i), path+"[]", seen)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			collectUnknown(v.MapIndex(key), fmt.Sprintf("%s[%v]", path, key), seen)
+		}
+	case reflect.Struct:
+		if extra, ok := extraField(v); ok && !extra.IsNil() {
+			for name := range extra.Interface().(*RawFields).Unknown {
+				seen[joinPath(path, name)] = true
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if name, ok := jsonName(f); ok {
+				collectUnknown(v.Field(i), joinPath(path, strings.ToLower(name)), seen)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package goinvestigate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnknownFields(t *testing.T) {
+	t.Parallel()
+	var h DomainRRHistory
+	err := json.Unmarshal([]byte(`{
+		"rrs_tf": [{"first_seen": "2014/04/01/00", "rrs": [{"name": "a.ru", "ttl": 60, "new_rr": 1}]}],
+		"features": {"age": 3, "Country_Count": 2, "new_score": 0.5}
+	}`), &h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// field names are matched case-insensitively, as encoding/json does
+	if h.RRFeatures.Age != 3 || h.RRFeatures.CountryCount != 2 {
+		t.Fatalf("known fields weren't decoded: %+v", h.RRFeatures)
+	}
+
+	if h.Extra != nil {
+		t.Fatalf("unexpected unknown fields %v", h.Extra.Unknown)
+	}
+
+	if string(h.RRFeatures.Extra.Unknown["new_score"]) != "0.5" {
+		t.Fatalf("new_score not kept: %+v", h.RRFeatures.Extra)
+	}
+
+	ref := []string{"features.new_score", "rrs_tf[].rrs[].new_rr"}
+	if fields := unknownFields(&h); !reflect.DeepEqual(fields, ref) {
+		t.Fatalf("%v should be %v", fields, ref)
+	}
+}
+
+func TestAttachRaw(t *testing.T) {
+	t.Parallel()
+	body := []byte(`{"Lat": 1.5, "Lon": 2.5}`)
+	var loc Location
+	if err := json.Unmarshal(body, &loc); err != nil {
+		t.Fatal(err)
+	}
+	attachRaw(&loc, body)
+	if string(loc.Extra.Raw) != string(body) {
+		t.Fatalf("%s should be %s", loc.Extra.Raw, body)
+	}
+
+	body = []byte(`[{"name": "a.ru", "id": 1}, {"name": "b.ru", "id": 2}]`)
+	var domains []MaliciousDomain
+	if err := json.Unmarshal(body, &domains); err != nil {
+		t.Fatal(err)
+	}
+	attachRaw(&domains, body)
+	if string(domains[1].Extra.Raw) != `{"name": "b.ru", "id": 2}` {
+		t.Fatalf("unexpected raw JSON %s", domains[1].Extra.Raw)
+	}
+
+	body = []byte(`{"a.ru": {"status": 1}}`)
+	cats := make(map[string]DomainCategorization)
+	if err := json.Unmarshal(body, &cats); err != nil {
+		t.Fatal(err)
+	}
+	attachRaw(&cats, body)
+	if string(cats["a.ru"].Extra.Raw) != `{"status": 1}` {
+		t.Fatalf("unexpected raw JSON %s", cats["a.ru"].Extra.Raw)
+	}
+}
+
+func TestDecodeMode(t *testing.T) {
+	t.Parallel()
+	lenient, srv := newTestClient(t, WithRawResponses())
+	srv.SetResponse("/security/name/www.test.com.json", `{"dga_score": -1.5, "found": true, "new_score": 7}`)
+	sec, err := lenient.Security("www.test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sec.DGAScore != -1.5 || !sec.Found || string(sec.Extra.Unknown["new_score"]) != "7" {
+		t.Fatalf("unexpected response %+v", sec)
+	}
+
+	if !strings.Contains(string(sec.Extra.Raw), `"new_score": 7`) {
+		t.Fatalf("raw JSON not kept: %s", sec.Extra.Raw)
+	}
+
+	var logged bytes.Buffer
+	warn := New(srv.Key, WithBaseURL(srv.URL), WithDecodeMode(DecodeWarn), WithLogger(log.New(&logged, "", 0)))
+	if _, err := warn.Security("www.test.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(logged.String(), "new_score") {
+		t.Fatalf("unknown field not logged: %q", logged.String())
+	}
+
+	strict := New(srv.Key, WithBaseURL(srv.URL), WithDecodeMode(DecodeStrict), WithRawResponses())
+	strictOut, err := strict.Security("www.test.com")
+	var drift *SchemaDriftError
+	if !errors.As(err, &drift) || !errors.Is(err, ErrSchemaDrift) {
+		t.Fatalf("%v should be a *SchemaDriftError", err)
+	}
+
+	if strictOut != nil {
+		t.Fatalf("%+v should not be returned along with the error", strictOut)
+	}
+
+	if !reflect.DeepEqual(drift.Fields, []string{"new_score"}) {
+		t.Fatalf("unexpected fields %v", drift.Fields)
+	}
+
+	// the value is still filled in for GetParse
+	var out SecurityFeatures
+	err = strict.GetParse("/security/name/www.test.com.json", &out)
+	if !errors.Is(err, ErrSchemaDrift) || out.DGAScore != -1.5 {
+		t.Fatalf("unexpected response %+v, %v", out, err)
+	}
+
+	// and returned by GetJSON, along with the fields asked to be kept
+	jsonOut, err := GetJSON[SecurityFeatures](context.Background(), strict, "/security/name/www.test.com.json")
+	if !errors.Is(err, ErrSchemaDrift) || jsonOut.DGAScore != -1.5 ||
+		jsonOut.Extra == nil || len(jsonOut.Extra.Raw) == 0 || jsonOut.Extra.Unknown["new_score"] == nil {
+		t.Fatalf("unexpected response %+v, %v", jsonOut, err)
+	}
+}
+
+func TestPairListResults(t *testing.T) {
+	t.Parallel()
+	rawInv, srv := newTestClient(t, WithRawResponses())
+	srv.SetResponse("/recommendations/name/www.test.com.json",
+		`{"pfs2": [["download.example.com", 0.5]], "found": true, "new_field": 1}`)
+
+	co, err := rawInv.CooccurrencesResult("www.test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !co.Found || len(co.Cooccurrences) != 1 || co.Cooccurrences[0].Score != 0.5 {
+		t.Fatalf("unexpected result %+v", co)
+	}
+
+	if co.Extra == nil || string(co.Extra.Unknown["new_field"]) != "1" || len(co.Extra.Raw) == 0 {
+		t.Fatalf("extra fields weren't kept: %+v", co.Extra)
+	}
+
+	related, err := rawInv.RelatedDomainsResult("www.test.com")
+	if err != nil || !related.Found || len(related.RelatedDomains) != 3 || related.RelatedDomains[0].Score != 10 {
+		t.Fatalf("got %+v, %v", related, err)
+	}
+
+	var list CooccurrenceList
+	if err := json.Unmarshal([]byte(`{"pfs2": [["a.com", "high"]]}`), &list); !errors.Is(err, ErrMalformedResponse) {
+		t.Fatalf("%v should be %v", err, ErrMalformedResponse)
+	}
+}
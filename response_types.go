@@ -6,10 +6,22 @@ import (
 	"fmt"
 )
 
+// Every response type keeps the fields of its JSON object which it has no
+// field for in Extra, so that additions to the API aren't lost. See
+// RawFields. The related domains and cooccurrences are lists of pairs,
+// which have no fields of their own; their responses are kept whole in
+// RelatedDomainsResult and CooccurrencesResult.
+
 type DomainCategorization struct {
-	Status             int
-	ContentCategories  []string `json:"content_categories"`
-	SecurityCategories []string `json:"security_categories"`
+	Status             DomainStatus
+	ContentCategories  []string   `json:"content_categories"`
+	SecurityCategories []string   `json:"security_categories"`
+	Extra              *RawFields `json:"-"`
+}
+
+func (dc *DomainCategorization) UnmarshalJSON(b []byte) error {
+	type alias DomainCategorization
+	return decodeFields(b, (*alias)(dc), &dc.Extra)
 }
 
 type Cooccurrence struct {
@@ -17,54 +29,37 @@ type Cooccurrence struct {
 	Score  float64
 }
 
+// The API gives each cooccurrence as a [domain, score] pair.
+func (c *Cooccurrence) UnmarshalJSON(b []byte) error {
+	return decodePair(b, &c.Domain, &c.Score)
+}
+
+// The cooccurrences of a domain, along with the rest of the response.
+type CooccurrencesResult struct {
+	// Whether there is any data for the domain
+	Found         bool
+	Cooccurrences []Cooccurrence `json:"pfs2"`
+	Extra         *RawFields     `json:"-"`
+}
+
+func (r *CooccurrencesResult) UnmarshalJSON(b []byte) error {
+	type alias CooccurrencesResult
+	return decodeFields(b, (*alias)(r), &r.Extra)
+}
+
+// The cooccurrences in a CooccurrencesResult. Unmarshalling the response
+// into a CooccurrenceList drops its other fields; unmarshal it into a
+// CooccurrencesResult to keep them.
 type CooccurrenceList []Cooccurrence
 
 func (r *CooccurrenceList) UnmarshalJSON(b []byte) error {
-	var raw map[string]interface{}
-	err := json.Unmarshal(b, &raw)
-	if err != nil {
+	var result CooccurrencesResult
+	if err := json.Unmarshal(b, &result); err != nil {
 		return err
 	}
-	malfErr := errors.New(fmt.Sprintf("malformed object: %v", raw))
-	pfs2, ok := raw["pfs2"]
-
-	// empty list
-	if !ok {
+	*r = result.Cooccurrences
+	if *r == nil {
 		*r = CooccurrenceList{}
-		return nil
-	}
-
-	pfs2List, ok := pfs2.([]interface{})
-	if !ok {
-		return errors.New("Could not convert pfs2List to []interface{}")
-	}
-
-	*r = make([]Cooccurrence, len(pfs2List))
-	for i, item := range pfs2List {
-		// convert the entry into its double list
-		itemList, ok := item.([]interface{})
-		if !ok {
-			r = nil
-			return errors.New("Could not convert item to []interface{}")
-		}
-
-		// extract the domain and score
-		d, ok := itemList[0].(string)
-		if !ok {
-			r = nil
-			return malfErr
-		}
-		s, ok := itemList[1].(float64)
-		if !ok {
-			r = nil
-			return malfErr
-		}
-
-		// put the domain into the list
-		(*r)[i] = Cooccurrence{
-			Domain: d,
-			Score:  s,
-		}
 	}
 	return nil
 }
@@ -74,55 +69,65 @@ type RelatedDomain struct {
 	Score  int
 }
 
+// The API gives each related domain as a [domain, score] pair.
+func (rd *RelatedDomain) UnmarshalJSON(b []byte) error {
+	var score float64
+	if err := decodePair(b, &rd.Domain, &score); err != nil {
+		return err
+	}
+	rd.Score = int(score)
+	return nil
+}
+
+// The related domains of a domain, along with the rest of the response.
+type RelatedDomainsResult struct {
+	// Whether there is any data for the domain
+	Found          bool
+	RelatedDomains []RelatedDomain `json:"tb1"`
+	Extra          *RawFields      `json:"-"`
+}
+
+func (r *RelatedDomainsResult) UnmarshalJSON(b []byte) error {
+	type alias RelatedDomainsResult
+	return decodeFields(b, (*alias)(r), &r.Extra)
+}
+
+// The related domains in a RelatedDomainsResult. Unmarshalling the response
+// into a RelatedDomainList drops its other fields; unmarshal it into a
+// RelatedDomainsResult to keep them.
 type RelatedDomainList []RelatedDomain
 
 func (r *RelatedDomainList) UnmarshalJSON(b []byte) error {
-	var raw map[string]interface{}
-	err := json.Unmarshal(b, &raw)
-	if err != nil {
+	var result RelatedDomainsResult
+	if err := json.Unmarshal(b, &result); err != nil {
 		return err
 	}
-	malfErr := errors.New(fmt.Sprintf("malformed object: %v", raw))
-	tb1, ok := raw["tb1"]
-
-	// empty list
-	if !ok {
+	*r = result.RelatedDomains
+	if *r == nil {
 		*r = RelatedDomainList{}
-		return nil
+	}
+	return nil
+}
+
+// Decodes a [name, score] pair.
+func decodePair(b []byte, name *string, score *float64) error {
+	var pair []interface{}
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedResponse, err)
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("%w: malformed pair %s", ErrMalformedResponse, b)
 	}
 
-	tb1List, ok := tb1.([]interface{})
+	n, ok := pair[0].(string)
 	if !ok {
-		return errors.New("Could not convert tb1List to []interface{}")
+		return fmt.Errorf("%w: malformed pair %s", ErrMalformedResponse, b)
 	}
-
-	*r = make([]RelatedDomain, len(tb1List))
-	for i, item := range tb1List {
-		// convert the entry into its double list
-		itemList, ok := item.([]interface{})
-		if !ok {
-			r = nil
-			return errors.New("Could not convert item to []interface{}")
-		}
-
-		// extract the domain and score
-		d, ok := itemList[0].(string)
-		if !ok {
-			r = nil
-			return malfErr
-		}
-		s, ok := itemList[1].(float64)
-		if !ok {
-			r = nil
-			return malfErr
-		}
-
-		// put the domain into the list
-		(*r)[i] = RelatedDomain{
-			Domain: d,
-			Score:  int(s),
-		}
+	s, ok := pair[1].(float64)
+	if !ok {
+		return fmt.Errorf("%w: malformed pair %s", ErrMalformedResponse, b)
 	}
+	*name, *score = n, s
 	return nil
 }
 
@@ -179,17 +184,36 @@ type SecurityFeatures struct {
 	KSTest                 float64 `json:"ks_test"`
 	Attack                 string
 	ThreatType             string `json:"threat_type"`
+	Found                  bool
+	Extra                  *RawFields `json:"-"`
+}
+
+func (sf *SecurityFeatures) UnmarshalJSON(b []byte) error {
+	type alias SecurityFeatures
+	return decodeFields(b, (*alias)(sf), &sf.Extra)
 }
 
 type PeriodType struct {
-	Begin string
-	End   string
+	Begin Timestamp
+	End   Timestamp
+	Extra *RawFields `json:"-"`
+}
+
+func (p *PeriodType) UnmarshalJSON(b []byte) error {
+	type alias PeriodType
+	return decodeFields(b, (*alias)(p), &p.Extra)
 }
 
 type DomainTag struct {
 	Url      string
 	Category string
 	Period   PeriodType
+	Extra    *RawFields `json:"-"`
+}
+
+func (dt *DomainTag) UnmarshalJSON(b []byte) error {
+	type alias DomainTag
+	return decodeFields(b, (*alias)(dt), &dt.Extra)
 }
 
 type ResourceRecord struct {
@@ -198,17 +222,35 @@ type ResourceRecord struct {
 	Class string
 	Type  string
 	RR    string
+	Extra *RawFields `json:"-"`
+}
+
+func (rr *ResourceRecord) UnmarshalJSON(b []byte) error {
+	type alias ResourceRecord
+	return decodeFields(b, (*alias)(rr), &rr.Extra)
 }
 
 type ResourceRecordPeriod struct {
-	FirstSeen string `json:"first_seen"`
-	LastSeen  string `json:"last_seen"`
+	FirstSeen Timestamp `json:"first_seen"`
+	LastSeen  Timestamp `json:"last_seen"`
 	RRs       []ResourceRecord
+	Extra     *RawFields `json:"-"`
+}
+
+func (rrp *ResourceRecordPeriod) UnmarshalJSON(b []byte) error {
+	type alias ResourceRecordPeriod
+	return decodeFields(b, (*alias)(rrp), &rrp.Extra)
 }
 
 type Location struct {
-	Lat float64
-	Lon float64
+	Lat   float64
+	Lon   float64
+	Extra *RawFields `json:"-"`
+}
+
+func (l *Location) UnmarshalJSON(b []byte) error {
+	type alias Location
+	return decodeFields(b, (*alias)(l), &l.Extra)
 }
 
 type DomainResourceRecordFeatures struct {
@@ -219,45 +261,79 @@ type DomainResourceRecordFeatures struct {
 	TTLsMedian      float64  `json:"ttls_median"`
 	TTLsStdDev      float64  `json:"ttls_stddev"`
 	CountryCodes    []string `json:"country_codes"`
+	CountryCount    int      `json:"country_count"`
 	ASNs            []int
+	ASNsCount       int `json:"asns_count"`
 	Prefixes        []string
+	PrefixesCount   int     `json:"prefixes_count"`
 	RIPSCount       int     `json:"rips"`
 	RIPSDiversity   float64 `json:"div_rips"`
 	Locations       []Location
+	LocationsCount  int     `json:"locations_count"`
 	GeoDistanceSum  float64 `json:"geo_distance_sum"`
 	GeoDistanceMean float64 `json:"geo_distance_mean"`
 	NonRoutable     bool    `json:"non_routable"`
 	MailExchanger   bool    `json:"mail_exchanger"`
 	CName           bool
-	FFCandidate     bool    `json:"ff_candidate"`
-	RIPSStability   float64 `json:"rips_stability"`
-	BaseDomain      string  `json:"base_domain"`
-	IsSubdomain     bool    `json:"is_subdomain"`
+	FFCandidate     bool       `json:"ff_candidate"`
+	RIPSStability   float64    `json:"rips_stability"`
+	BaseDomain      string     `json:"base_domain"`
+	IsSubdomain     bool       `json:"is_subdomain"`
+	Extra           *RawFields `json:"-"`
+}
+
+func (f *DomainResourceRecordFeatures) UnmarshalJSON(b []byte) error {
+	type alias DomainResourceRecordFeatures
+	return decodeFields(b, (*alias)(f), &f.Extra)
 }
 
 type DomainRRHistory struct {
 	RRPeriods  []ResourceRecordPeriod       `json:"rrs_tf"`
 	RRFeatures DomainResourceRecordFeatures `json:"features"`
+	Extra      *RawFields                   `json:"-"`
+}
+
+func (h *DomainRRHistory) UnmarshalJSON(b []byte) error {
+	type alias DomainRRHistory
+	return decodeFields(b, (*alias)(h), &h.Extra)
 }
 
 type IPResourceRecordFeatures struct {
-	RRCount   int     `json:"rr_count"`
-	LD2Count  int     `json:"ld2_count"`
-	LD3Count  int     `json:"ld3_count"`
-	LD21Count int     `json:"ld2_1_count"`
-	LD22Count int     `json:"ld2_2_count"`
-	DivLD2    float64 `json:"div_ld2"`
-	DivLD3    float64 `json:"div_ld3"`
-	DivLD21   float64 `json:"div_ld2_1"`
-	DivLD22   float64 `json:"div_ld2_2"`
+	RRCount   int        `json:"rr_count"`
+	LD2Count  int        `json:"ld2_count"`
+	LD3Count  int        `json:"ld3_count"`
+	LD21Count int        `json:"ld2_1_count"`
+	LD22Count int        `json:"ld2_2_count"`
+	DivLD2    float64    `json:"div_ld2"`
+	DivLD3    float64    `json:"div_ld3"`
+	DivLD21   float64    `json:"div_ld2_1"`
+	DivLD22   float64    `json:"div_ld2_2"`
+	Extra     *RawFields `json:"-"`
+}
+
+func (f *IPResourceRecordFeatures) UnmarshalJSON(b []byte) error {
+	type alias IPResourceRecordFeatures
+	return decodeFields(b, (*alias)(f), &f.Extra)
 }
 
 type IPRRHistory struct {
 	RRs        []ResourceRecord
 	RRFeatures IPResourceRecordFeatures `json:"features"`
+	Extra      *RawFields               `json:"-"`
+}
+
+func (h *IPRRHistory) UnmarshalJSON(b []byte) error {
+	type alias IPRRHistory
+	return decodeFields(b, (*alias)(h), &h.Extra)
 }
 
 type MaliciousDomain struct {
 	Domain string `json:"name"`
 	Id     int
+	Extra  *RawFields `json:"-"`
+}
+
+func (md *MaliciousDomain) UnmarshalJSON(b []byte) error {
+	type alias MaliciousDomain
+	return decodeFields(b, (*alias)(md), &md.Extra)
 }
@@ -272,16 +272,16 @@ func TestUnmarshalDomainTags(t *testing.T) {
 			Url:      "http://ancgrli.prophp.org/",
 			Category: "Malware",
 			Period: PeriodType{
-				Begin: "2014-04-07",
-				End:   "Current",
+				Begin: mustTimestamp(t, "2014-04-07"),
+				End:   mustTimestamp(t, "Current"),
 			},
 		},
 		DomainTag{
 			Url:      "http://ancgrli.prophp.org/34/45791.html",
 			Category: "Malware",
 			Period: PeriodType{
-				Begin: "2014-03-04",
-				End:   "2014-03-05",
+				Begin: mustTimestamp(t, "2014-03-04"),
+				End:   mustTimestamp(t, "2014-03-05"),
 			},
 		},
 	}
@@ -373,8 +373,8 @@ func TestUnmarshalDomainRRHistory(t *testing.T) {
 	refDRR := DomainRRHistory{
 		RRPeriods: []ResourceRecordPeriod{
 			ResourceRecordPeriod{
-				FirstSeen: "2013-07-31",
-				LastSeen:  "2013-10-17",
+				FirstSeen: mustTimestamp(t, "2013-07-31"),
+				LastSeen:  mustTimestamp(t, "2013-10-17"),
 				RRs: []ResourceRecord{
 					ResourceRecord{
 						Name:  "example.com.",
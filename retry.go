@@ -0,0 +1,177 @@
+package goinvestigate
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// A RetryPolicy decides whether a failed request should be tried again, and
+// how long to wait before doing so.
+type RetryPolicy interface {
+	// Retry is called after every failed attempt. attempt is the number of
+	// attempts made so far, starting at 1, and elapsed is the time since the
+	// first attempt was made. resp is the failed response, or nil if the
+	// request failed with the transport error err. resp's body must not be
+	// read.
+	//
+	// It returns how long to wait before the next attempt, and false if the
+	// request should not be retried at all.
+	Retry(attempt int, elapsed time.Duration, resp *http.Response, err error) (time.Duration, bool)
+}
+
+// A RetryEvent describes a retry which is about to happen. It is passed to
+// the hook given with WithRetryHook.
+type RetryEvent struct {
+	Request *http.Request
+	// The number of the attempt which failed, starting at 1
+	Attempt int
+	// How long the client will wait before the next attempt
+	Wait time.Duration
+	// Why the attempt failed: an *APIError, or a transport error
+	Err error
+}
+
+// ExponentialBackoff is a RetryPolicy which retries transport errors, server
+// errors and rate limited (429) responses, doubling the wait after each
+// attempt. A Retry-After header on the response takes precedence over the
+// computed wait.
+type ExponentialBackoff struct {
+	// The maximum number of attempts, including the first one.
+	// Zero means no limit.
+	MaxAttempts int
+
+	// Give up once this much time has passed since the first attempt,
+	// or would have passed after the next wait. Zero means no limit.
+	MaxElapsed time.Duration
+
+	// The wait after the first attempt, which is doubled after each
+	// further one, up to MaxDelay if it is non-zero.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// The fraction of each wait, from 0 to 1, which is randomized so that
+	// concurrent clients don't retry in lock step.
+	Jitter float64
+}
+
+// Returns the retry policy used by clients which aren't given one with
+// WithRetryPolicy. It makes up to 6 attempts in total, over at most 2 minutes.
+func DefaultRetryPolicy() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		MaxAttempts: maxTries + 1,
+		MaxElapsed:  2 * time.Minute,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+func (b *ExponentialBackoff) Retry(attempt int, elapsed time.Duration, resp *http.Response, err error) (time.Duration, bool) {
+	if b.MaxAttempts > 0 && attempt >= b.MaxAttempts {
+		return 0, false
+	}
+
+	if resp != nil && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return 0, false
+	}
+
+	wait, ok := retryAfter(resp)
+	if !ok {
+		wait = b.backoff(attempt)
+	}
+
+	if b.MaxElapsed > 0 && elapsed+wait > b.MaxElapsed {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// The jittered wait after the given attempt.
+func (b *ExponentialBackoff) backoff(attempt int) time.Duration {
+	wait := b.BaseDelay
+	for i := 1; i < attempt && (b.MaxDelay <= 0 || wait < b.MaxDelay); i++ {
+		wait *= 2
+	}
+
+	if b.MaxDelay > 0 && wait > b.MaxDelay {
+		wait = b.MaxDelay
+	}
+
+	if b.Jitter > 0 {
+		jitter := time.Duration(float64(wait) * b.Jitter)
+		wait = wait - jitter + time.Duration(rand.Int63n(int64(jitter)+1))
+	}
+
+	return wait
+}
+
+// Parses the Retry-After header of the given response, which may be either
+// a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// Waits for d to pass, or for ctx to be done, whichever happens first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Whether req can be sent again. Requests with a body can only be retried if
+// the body can be rewound, which is the case for requests made by Get and
+// Post with a *bytes.Reader, *bytes.Buffer or *strings.Reader.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// Prepares req for the given attempt, giving retries a fresh copy of the body.
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, nil
+}
@@ -0,0 +1,204 @@
+package goinvestigate
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dead10ck/goinvestigate/goinvestigatetest"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Parallel()
+	policy := &ExponentialBackoff{
+		MaxAttempts: 4,
+		BaseDelay:   time.Second,
+		MaxDelay:    3 * time.Second,
+	}
+	serverErr := &http.Response{StatusCode: http.StatusInternalServerError}
+
+	for attempt, ref := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		wait, ok := policy.Retry(attempt+1, 0, serverErr, nil)
+		if !ok || wait != ref {
+			t.Fatalf("attempt %d: waited %v (retry: %v), should wait %v", attempt+1, wait, ok, ref)
+		}
+	}
+
+	if _, ok := policy.Retry(4, 0, serverErr, nil); ok {
+		t.Fatal("should give up after MaxAttempts")
+	}
+
+	if _, ok := policy.Retry(1, 0, &http.Response{StatusCode: http.StatusNotFound}, nil); ok {
+		t.Fatal("should not retry a 404")
+	}
+
+	if _, ok := policy.Retry(1, 0, nil, errors.New("connection reset")); !ok {
+		t.Fatal("should retry a transport error")
+	}
+
+	policy.MaxElapsed = 5 * time.Second
+	if _, ok := policy.Retry(2, 4*time.Second, serverErr, nil); ok {
+		t.Fatal("should give up when the wait would exceed MaxElapsed")
+	}
+}
+
+func TestExponentialBackoffJitter(t *testing.T) {
+	t.Parallel()
+	policy := &ExponentialBackoff{BaseDelay: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		wait, _ := policy.Retry(1, 0, nil, errors.New("timeout"))
+		if wait < 500*time.Millisecond || wait > time.Second {
+			t.Fatalf("jittered wait %v should be within [500ms, 1s]", wait)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+	policy := &ExponentialBackoff{BaseDelay: time.Millisecond}
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"7"}},
+	}
+
+	if wait, ok := policy.Retry(1, 0, resp, nil); !ok || wait != 7*time.Second {
+		t.Fatalf("waited %v (retry: %v), should wait 7s", wait, ok)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if wait, ok := policy.Retry(1, 0, resp, nil); !ok || wait < 59*time.Minute || wait > time.Hour {
+		t.Fatalf("waited %v (retry: %v), should wait about an hour", wait, ok)
+	}
+}
+
+func TestRequestRetries(t *testing.T) {
+	t.Parallel()
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch len(bodies) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"www.test.com": {"status": 1}}`))
+		}
+	}))
+	defer ts.Close()
+
+	var events []RetryEvent
+	retryInv := New("test_key",
+		WithBaseURL(ts.URL),
+		WithLogger(log.New(ioutil.Discard, "", 0)),
+		WithRetryPolicy(&ExponentialBackoff{MaxAttempts: 3}),
+		WithRetryHook(func(e RetryEvent) { events = append(events, e) }),
+	)
+
+	out, err := retryInv.Categorizations([]string{"www.test.com"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out["www.test.com"].Status != 1 {
+		t.Fatalf("unexpected response %v", out)
+	}
+
+	for _, body := range bodies {
+		if body != `["www.test.com"]` {
+			t.Fatalf("request body was not resent: %q", bodies)
+		}
+	}
+
+	if len(events) != 2 ||
+		!errors.Is(events[0].Err, ErrRateLimited) ||
+		events[0].Attempt != 1 ||
+		events[1].Attempt != 2 {
+		t.Fatalf("unexpected retry events: %+v", events)
+	}
+}
+
+func TestRequestRetryTransportError(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	retryInv := New("test_key",
+		WithLogger(log.New(ioutil.Discard, "", 0)),
+		WithRetryPolicy(&ExponentialBackoff{MaxAttempts: 2}),
+		WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return nil, errors.New("connection refused")
+		})),
+	)
+
+	if _, err := retryInv.Security("www.test.com"); err == nil {
+		t.Fatal("should return the transport error")
+	}
+
+	if calls != 2 {
+		t.Fatalf("made %d requests, should have made 2", calls)
+	}
+}
+
+func TestRequestRetryCanceled(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	retryInv := New("test_key",
+		WithBaseURL(ts.URL),
+		WithLogger(log.New(ioutil.Discard, "", 0)),
+		WithRetryPolicy(&ExponentialBackoff{BaseDelay: time.Hour}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := retryInv.SecurityContext(ctx, "www.test.com")
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("%v should be %v", err, context.DeadlineExceeded)
+	}
+
+	if time.Since(start) > 10*time.Second {
+		t.Fatal("the backoff wait was not aborted")
+	}
+}
+
+func TestRequestRetriesInjectedFaults(t *testing.T) {
+	t.Parallel()
+	retryInv, faultSrv := newTestClient(t,
+		WithLogger(log.New(ioutil.Discard, "", 0)),
+		WithRetryPolicy(&ExponentialBackoff{MaxAttempts: 3}),
+	)
+	faultSrv.Inject("/security/", goinvestigatetest.RateLimitFault(0, 1))
+	faultSrv.Inject("/security/", goinvestigatetest.ErrorFault(http.StatusBadGateway, 1))
+	faultSrv.Inject("/security/", goinvestigatetest.MalformedFault(1))
+
+	// the malformed response is a success as far as retries go
+	if _, err := retryInv.Security("www.test.com"); !errors.Is(err, ErrMalformedResponse) {
+		t.Fatalf("%v should be %v", err, ErrMalformedResponse)
+	}
+
+	out, err := retryInv.Security("www.test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.DGAScore != 38.301771886101335 {
+		t.Fatalf("unexpected response %+v", out)
+	}
+
+	if n := len(faultSrv.Requests()); n != 4 {
+		t.Fatalf("made %d requests, should have made 4", n)
+	}
+}
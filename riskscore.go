@@ -0,0 +1,71 @@
+package goinvestigate
+
+import (
+	"context"
+	"fmt"
+)
+
+// The risk score of a domain, from 0 (no risk) to 100 (the highest risk),
+// along with the indicators it was computed from.
+type DomainRiskScore struct {
+	RiskScore  int `json:"risk_score"`
+	Indicators []RiskIndicator
+	Extra      *RawFields `json:"-"`
+}
+
+func (rs *DomainRiskScore) UnmarshalJSON(b []byte) error {
+	type alias DomainRiskScore
+	return decodeFields(b, (*alias)(rs), &rs.Extra)
+}
+
+// The indicator with the given name or ID, if the score has it.
+func (rs DomainRiskScore) Indicator(name string) (RiskIndicator, bool) {
+	for _, indicator := range rs.Indicators {
+		if indicator.Indicator == name || indicator.IndicatorID == name {
+			return indicator, true
+		}
+	}
+	return RiskIndicator{}, false
+}
+
+// One of the indicators a risk score is computed from, e.g. "Geo Popularity
+// Score" or "Lexical".
+type RiskIndicator struct {
+	Indicator   string
+	IndicatorID string `json:"indicator_id"`
+	// The indicator's raw value, on its own scale
+	Score float64
+	// The value scaled to 0-100, the same as the risk score
+	NormalizedScore float64 `json:"normalized_score"`
+	// How much the indicator counts towards the risk score, where the API
+	// gives it
+	Weight float64
+	Extra  *RawFields `json:"-"`
+}
+
+func (ri *RiskIndicator) UnmarshalJSON(b []byte) error {
+	type alias RiskIndicator
+	return decodeFields(b, (*alias)(ri), &ri.Extra)
+}
+
+// Get the risk score of the given domain, and the indicators which make it
+// up.
+//
+// For details, see https://sgraph.opendns.com/docs/api#risk-score
+func (inv *Investigate) RiskScore(domain string) (*DomainRiskScore, error) {
+	return inv.RiskScoreContext(context.Background(), domain)
+}
+
+// Like RiskScore, but the request is bound to ctx.
+func (inv *Investigate) RiskScoreContext(ctx context.Context, domain string) (*DomainRiskScore, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
+	resp := new(DomainRiskScore)
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["risk_score"], segment), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,44 @@
+package goinvestigate
+
+import (
+	"testing"
+)
+
+func TestRiskScore(t *testing.T) {
+	t.Parallel()
+	out, err := inv.RiskScore("bibikun.ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.RiskScore < 0 || out.RiskScore > 100 || len(out.Indicators) == 0 {
+		t.Fatalf("unexpected risk score %+v", out)
+	}
+}
+
+func TestRiskScoreBulk(t *testing.T) {
+	t.Parallel()
+	riskInv, srv := newTestClient(t, WithDecodeMode(DecodeStrict))
+	srv.SetResponse("/domains/risk-score/www.amazon.com", `{"risk_score": 3, "indicators": [
+		{"indicator": "Lexical", "indicator_id": "Lexical", "normalized_score": 1, "score": 0.001, "weight": 0.25}]}`)
+
+	domains := []string{"bibikun.ru", "www.amazon.com", "not a domain"}
+	results := riskInv.RiskScoreBulk(domains)
+
+	if results[0].Err != nil || results[0].Value.RiskScore != 82 {
+		t.Fatalf("unexpected result %+v", results[0])
+	}
+
+	if geo, ok := results[0].Value.Indicator("Geo Popularity Score"); !ok || geo.NormalizedScore != 45 || geo.Score != -0.4 {
+		t.Fatalf("unexpected indicator %+v", geo)
+	}
+
+	lexical, ok := results[1].Value.Indicator("Lexical")
+	if results[1].Err != nil || !ok || lexical.Weight != 0.25 {
+		t.Fatalf("unexpected result %+v", results[1])
+	}
+
+	if results[2].Err == nil {
+		t.Fatal("invalid domain should fail")
+	}
+}
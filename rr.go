@@ -0,0 +1,169 @@
+package goinvestigate
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+var (
+	// A ResourceRecord accessor was called on a record of another type,
+	// e.g. MX on an A record.
+	ErrRecordType = errors.New("wrong record type")
+
+	// The value of a ResourceRecord could not be parsed for its type.
+	ErrMalformedRecord = errors.New("malformed record")
+)
+
+// The value of an MX record.
+type MX struct {
+	Preference uint16
+	Exchange   string
+}
+
+func (rr ResourceRecord) checkType(types ...string) error {
+	for _, t := range types {
+		if strings.EqualFold(rr.Type, t) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s record, not %s", ErrRecordType, rr.Type, strings.Join(types, " or "))
+}
+
+func (rr ResourceRecord) malformed(reason string) error {
+	return fmt.Errorf("%w: %s record %q: %s", ErrMalformedRecord, rr.Type, rr.RR, reason)
+}
+
+// The address of an A or AAAA record.
+func (rr ResourceRecord) Addr() (netip.Addr, error) {
+	if err := rr.checkType("A", "AAAA"); err != nil {
+		return netip.Addr{}, err
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSpace(rr.RR))
+	if err != nil {
+		return netip.Addr{}, rr.malformed(err.Error())
+	}
+
+	if strings.EqualFold(rr.Type, "A") != addr.Is4() {
+		return netip.Addr{}, rr.malformed("wrong address family")
+	}
+	return addr, nil
+}
+
+// The host name an NS, CNAME or PTR record points to.
+func (rr ResourceRecord) Host() (string, error) {
+	if err := rr.checkType("NS", "CNAME", "PTR"); err != nil {
+		return "", err
+	}
+
+	host := strings.TrimSpace(rr.RR)
+	if host == "" || strings.ContainsAny(host, " \t") {
+		return "", rr.malformed("not a host name")
+	}
+	return host, nil
+}
+
+// The preference and exchange of an MX record.
+func (rr ResourceRecord) MX() (MX, error) {
+	if err := rr.checkType("MX"); err != nil {
+		return MX{}, err
+	}
+
+	fields := strings.Fields(rr.RR)
+	if len(fields) != 2 {
+		return MX{}, rr.malformed("should be a preference and an exchange")
+	}
+
+	pref, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return MX{}, rr.malformed("bad preference")
+	}
+	return MX{Preference: uint16(pref), Exchange: fields[1]}, nil
+}
+
+// The strings of a TXT record, with their quotes and escapes decoded. A
+// value with no quotes at all is taken as a single string.
+func (rr ResourceRecord) TXT() ([]string, error) {
+	if err := rr.checkType("TXT"); err != nil {
+		return nil, err
+	}
+
+	value := strings.TrimSpace(rr.RR)
+	if !strings.HasPrefix(value, `"`) {
+		return []string{value}, nil
+	}
+
+	var strs []string
+	for value != "" {
+		if value[0] != '"' {
+			return nil, rr.malformed("text outside of quotes")
+		}
+
+		str, rest, err := unquoteTXT(value[1:])
+		if err != nil {
+			return nil, rr.malformed(err.Error())
+		}
+		strs = append(strs, str)
+		value = strings.TrimLeft(rest, " \t")
+	}
+	return strs, nil
+}
+
+// Decodes a quoted TXT string up to its closing quote, returning the rest of
+// s after it. Escapes are either \X for a literal X, or \DDD for a byte in
+// decimal.
+func unquoteTXT(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i+3 < len(s) && isDigits(s[i+1:i+4]) {
+				n, _ := strconv.Atoi(s[i+1 : i+4])
+				if n > 255 {
+					return "", "", fmt.Errorf("bad escape \\%s", s[i+1:i+4])
+				}
+				b.WriteByte(byte(n))
+				i += 3
+			} else if i+1 < len(s) {
+				b.WriteByte(s[i+1])
+				i++
+			} else {
+				return "", "", errors.New("unterminated escape")
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// The value of the record, typed by its Type: a netip.Addr for A and AAAA,
+// a host name string for NS, CNAME and PTR, an MX for MX, and a []string
+// for TXT. Records of other types give their RR string as is.
+func (rr ResourceRecord) Value() (interface{}, error) {
+	switch strings.ToUpper(rr.Type) {
+	case "A", "AAAA":
+		return rr.Addr()
+	case "NS", "CNAME", "PTR":
+		return rr.Host()
+	case "MX":
+		return rr.MX()
+	case "TXT":
+		return rr.TXT()
+	}
+	return rr.RR, nil
+}
@@ -0,0 +1,85 @@
+package goinvestigate
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestResourceRecordAddr(t *testing.T) {
+	t.Parallel()
+	addr, err := ResourceRecord{Type: "A", RR: "93.184.216.119"}.Addr()
+	if err != nil || addr != netip.MustParseAddr("93.184.216.119") {
+		t.Fatalf("got %v, %v", addr, err)
+	}
+
+	addr, err = ResourceRecord{Type: "AAAA", RR: "2606:2800:220:1::248"}.Addr()
+	if err != nil || !addr.Is6() {
+		t.Fatalf("got %v, %v", addr, err)
+	}
+
+	if _, err := (ResourceRecord{Type: "A", RR: "2606:2800:220:1::248"}).Addr(); !errors.Is(err, ErrMalformedRecord) {
+		t.Fatalf("%v should be %v", err, ErrMalformedRecord)
+	}
+
+	if _, err := (ResourceRecord{Type: "MX", RR: "10 mx.example.com."}).Addr(); !errors.Is(err, ErrRecordType) {
+		t.Fatalf("%v should be %v", err, ErrRecordType)
+	}
+}
+
+func TestResourceRecordHostAndMX(t *testing.T) {
+	t.Parallel()
+	host, err := ResourceRecord{Type: "NS", RR: "a.iana-servers.net."}.Host()
+	if err != nil || host != "a.iana-servers.net." {
+		t.Fatalf("got %q, %v", host, err)
+	}
+
+	mx, err := ResourceRecord{Type: "MX", RR: "10 mx.example.com."}.MX()
+	if err != nil || mx != (MX{Preference: 10, Exchange: "mx.example.com."}) {
+		t.Fatalf("got %v, %v", mx, err)
+	}
+
+	for _, rr := range []string{"mx.example.com.", "70000 mx.example.com.", "10 a b"} {
+		if _, err := (ResourceRecord{Type: "MX", RR: rr}).MX(); !errors.Is(err, ErrMalformedRecord) {
+			t.Fatalf("%q: %v should be %v", rr, err, ErrMalformedRecord)
+		}
+	}
+}
+
+func TestResourceRecordTXT(t *testing.T) {
+	t.Parallel()
+	tests := map[string][]string{
+		`v=spf1 -all`:                     {"v=spf1 -all"},
+		`"v=spf1 -all"`:                   {"v=spf1 -all"},
+		`"part one" "part \"two\""`:       {"part one", `part "two"`},
+		`"caf\195\169" "back\\slash"`:     {"café", `back\slash`},
+		`"google-site-verification=abc" `: {"google-site-verification=abc"},
+	}
+
+	for rr, ref := range tests {
+		strs, err := ResourceRecord{Type: "TXT", RR: rr}.TXT()
+		if err != nil || !reflect.DeepEqual(strs, ref) {
+			t.Fatalf("%s: got %q, %v; should be %q", rr, strs, err, ref)
+		}
+	}
+
+	for _, rr := range []string{`"unterminated`, `"a" b`, `"\999"`} {
+		if _, err := (ResourceRecord{Type: "TXT", RR: rr}).TXT(); !errors.Is(err, ErrMalformedRecord) {
+			t.Fatalf("%s: %v should be %v", rr, err, ErrMalformedRecord)
+		}
+	}
+}
+
+func TestResourceRecordValue(t *testing.T) {
+	t.Parallel()
+	v, err := ResourceRecord{Type: "cname", RR: "www.example.com."}.Value()
+	if err != nil || v != "www.example.com." {
+		t.Fatalf("got %v, %v", v, err)
+	}
+
+	v, err = ResourceRecord{Type: "SOA", RR: "ns.example.com. admin.example.com. 1 2 3 4 5"}.Value()
+	if err != nil || v != "ns.example.com. admin.example.com. 1 2 3 4 5" {
+		t.Fatalf("got %v, %v", v, err)
+	}
+}
@@ -0,0 +1,245 @@
+package goinvestigate
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Progress reports how far along a streaming lookup is.
+type Progress struct {
+	// The number of items which were looked up successfully
+	Completed int
+	// The number of items whose lookup failed
+	Failed int
+	// The number of items which haven't been yielded yet
+	Remaining int
+}
+
+// A StreamOption configures a streaming lookup such as SecurityStream.
+type StreamOption func(*streamConfig)
+
+type streamConfig struct {
+	ordered  bool
+	buffer   int
+	progress func(Progress)
+}
+
+// Yield results in the same order as the items they are for, rather than
+// as soon as they complete. One slow lookup then holds back the results
+// after it, up to the stream's buffer.
+func StreamOrdered() StreamOption {
+	return func(c *streamConfig) {
+		c.ordered = true
+	}
+}
+
+// Let up to n results wait for the consumer before lookups are paused.
+// The default is the client's concurrency.
+func StreamBuffer(n int) StreamOption {
+	return func(c *streamConfig) {
+		if n > 0 {
+			c.buffer = n
+		}
+	}
+}
+
+// Call fn after each result is yielded. fn is called from the goroutine
+// which is ranging over the stream.
+func StreamProgress(fn func(Progress)) StreamOption {
+	return func(c *streamConfig) {
+		c.progress = fn
+	}
+}
+
+type indexedResult[T any] struct {
+	index int
+	Result[T]
+}
+
+// Looks up every item with lookup, making up to the client's concurrency of
+// requests at once, and yields each item with its result.
+func stream[T any](ctx context.Context, inv *Investigate, items []string, lookup func(context.Context, string) (T, error), opts ...StreamOption) iter.Seq2[string, Result[T]] {
+	return StreamLookup(ctx, inv.concurrency, items, lookup, opts...)
+}
+
+// Look up every item with lookup, running up to concurrency lookups at
+// once, and yield each item with its result. The streaming methods of
+// Investigate, such as SecurityStream, are built on it; it's exported so
+// that other implementations of Investigator can stream the same way.
+//
+// Lookups only run ahead of the consumer by the stream's buffer, so a slow
+// consumer slows down the lookups rather than piling up results. Breaking
+// out of the loop cancels the lookups which are still running. If ctx is
+// cancelled, the remaining items are yielded with its error.
+func StreamLookup[T any](ctx context.Context, concurrency int, items []string, lookup func(context.Context, string) (T, error), opts ...StreamOption) iter.Seq2[string, Result[T]] {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	cfg := streamConfig{buffer: concurrency}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(yield func(string, Result[T]) bool) {
+		// stop is closed when the consumer breaks out of the loop. Cancelling
+		// ctx itself doesn't stop the stream: the remaining lookups fail
+		// quickly with ctx's error instead, so that every item gets a result.
+		ctx, cancel := context.WithCancel(ctx)
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+		defer close(stop)
+
+		// a slot is taken for each item which is being looked up or waiting
+		// to be yielded, and given back once it has been yielded
+		slots := make(chan struct{}, concurrency+cfg.buffer)
+		indexes := make(chan int)
+		results := make(chan indexedResult[T], cfg.buffer)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(indexes)
+			for i := range items {
+				select {
+				case slots <- struct{}{}:
+				case <-stop:
+					return
+				}
+				select {
+				case indexes <- i:
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		for w := 0; w < concurrency && w < len(items); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					value, err := lookup(ctx, items[i])
+					select {
+					case results <- indexedResult[T]{i, Result[T]{items[i], value, err}}:
+					case <-stop:
+						return
+					}
+				}
+			}()
+		}
+
+		progress := Progress{Remaining: len(items)}
+		pending := make(map[int]Result[T])
+		next := 0
+
+		emit := func(result Result[T]) bool {
+			progress.Remaining--
+			if result.Err != nil {
+				progress.Failed++
+			} else {
+				progress.Completed++
+			}
+			if cfg.progress != nil {
+				cfg.progress(progress)
+			}
+			if !yield(result.Item, result) {
+				return false
+			}
+			<-slots
+			return true
+		}
+
+		for progress.Remaining > 0 {
+			r := <-results
+			if !cfg.ordered {
+				if !emit(r.Result) {
+					return
+				}
+				continue
+			}
+
+			pending[r.index] = r.Result
+			for {
+				result, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				if !emit(result) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Stream the Security Information of each of the given domains as it
+// is looked up.
+//
+// For details, see https://sgraph.opendns.com/docs/api#securityInfo
+func (inv *Investigate) SecurityStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[*SecurityFeatures]] {
+	return stream(ctx, inv, domains, inv.SecurityContext, opts...)
+}
+
+// Stream the related domains of each of the given domains as they are
+// looked up.
+//
+// For details, see https://sgraph.opendns.com/docs/api#relatedDomains
+func (inv *Investigate) RelatedDomainsStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[[]RelatedDomain]] {
+	return stream(ctx, inv, domains, inv.RelatedDomainsContext, opts...)
+}
+
+// Stream the cooccurrences of each of the given domains as they are
+// looked up.
+//
+// For details, see https://sgraph.opendns.com/docs/api#co-occurrences
+func (inv *Investigate) CooccurrencesStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[[]Cooccurrence]] {
+	return stream(ctx, inv, domains, inv.CooccurrencesContext, opts...)
+}
+
+// Stream the domain tagging dates of each of the given domains as they are
+// looked up.
+//
+// For details, see https://sgraph.opendns.com/docs/api#latest_tags
+func (inv *Investigate) DomainTagsStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[[]DomainTag]] {
+	return stream(ctx, inv, domains, inv.DomainTagsContext, opts...)
+}
+
+// Stream the RR (Resource Record) History of each of the given domains as
+// it is looked up.
+//
+// For details, see https://sgraph.opendns.com/docs/api#dnsrr_domain
+func (inv *Investigate) DomainRRHistoryStream(ctx context.Context, domains []string, queryType QueryType, opts ...StreamOption) iter.Seq2[string, Result[*DomainRRHistory]] {
+	return stream(ctx, inv, domains, func(ctx context.Context, domain string) (*DomainRRHistory, error) {
+		return inv.DomainRRHistoryContext(ctx, domain, queryType)
+	}, opts...)
+}
+
+// Stream the RR (Resource Record) History of each of the given IPs as it is
+// looked up.
+//
+// For details, see https://sgraph.opendns.com/docs/api#dnsrr_ip
+func (inv *Investigate) IpRRHistoryStream(ctx context.Context, ips []string, queryType QueryType, opts ...StreamOption) iter.Seq2[string, Result[*IPRRHistory]] {
+	return stream(ctx, inv, ips, func(ctx context.Context, ip string) (*IPRRHistory, error) {
+		return inv.IpRRHistoryContext(ctx, ip, queryType)
+	}, opts...)
+}
+
+// Stream the latest known malicious domains of each of the given IPs as
+// they are looked up.
+//
+// For details, see https://sgraph.opendns.com/docs/api#latest_domains
+func (inv *Investigate) LatestDomainsStream(ctx context.Context, ips []string, opts ...StreamOption) iter.Seq2[string, Result[[]string]] {
+	return stream(ctx, inv, ips, inv.LatestDomainsContext, opts...)
+}
+
+// Stream the risk score of each of the given domains as it is looked up.
+//
+// For details, see https://sgraph.opendns.com/docs/api#risk-score
+func (inv *Investigate) RiskScoreStream(ctx context.Context, domains []string, opts ...StreamOption) iter.Seq2[string, Result[*DomainRiskScore]] {
+	return stream(ctx, inv, domains, inv.RiskScoreContext, opts...)
+}
@@ -0,0 +1,126 @@
+package goinvestigate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testItems(n int) []string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf("%d.example.com", i)
+	}
+	return items
+}
+
+// sleeps longer for earlier items, so they complete out of order
+func reverseLookup(n int) func(context.Context, string) (string, error) {
+	return func(ctx context.Context, item string) (string, error) {
+		var i int
+		fmt.Sscanf(item, "%d.", &i)
+		time.Sleep(time.Duration(n-i) * time.Millisecond)
+		if i%5 == 0 {
+			return "", errors.New("lookup failed")
+		}
+		return item, nil
+	}
+}
+
+func TestStreamOrdered(t *testing.T) {
+	t.Parallel()
+	streamInv := New("test_key", WithConcurrency(4))
+	items := testItems(20)
+
+	var progress []Progress
+	i := 0
+	for item, result := range stream(context.Background(), streamInv, items, reverseLookup(20),
+		StreamOrdered(), StreamProgress(func(p Progress) { progress = append(progress, p) })) {
+		if item != items[i] || result.Item != items[i] {
+			t.Fatalf("result %d is for %s, should be for %s", i, item, items[i])
+		}
+		if (i%5 == 0) != (result.Err != nil) {
+			t.Fatalf("%s: unexpected error %v", item, result.Err)
+		}
+		i++
+	}
+
+	if i != len(items) {
+		t.Fatalf("got %d results for %d items", i, len(items))
+	}
+
+	last := progress[len(progress)-1]
+	if len(progress) != len(items) || last != (Progress{Completed: 16, Failed: 4, Remaining: 0}) {
+		t.Fatalf("unexpected progress %+v", last)
+	}
+}
+
+func TestStreamUnordered(t *testing.T) {
+	t.Parallel()
+	streamInv := New("test_key", WithConcurrency(20))
+	items := testItems(20)
+
+	seen := make(map[string]bool)
+	var order []string
+	for item := range stream(context.Background(), streamInv, items, reverseLookup(20)) {
+		seen[item] = true
+		order = append(order, item)
+	}
+
+	if len(seen) != len(items) {
+		t.Fatalf("got results for %d of %d items", len(seen), len(items))
+	}
+
+	// all lookups run at once, so the last item completes first
+	if order[0] == items[0] {
+		t.Fatalf("results should be yielded as they complete: %v", order)
+	}
+}
+
+func TestStreamBackpressure(t *testing.T) {
+	t.Parallel()
+	streamInv := New("test_key", WithConcurrency(2))
+	var started atomic.Int32
+	lookup := func(ctx context.Context, item string) (string, error) {
+		started.Add(1)
+		return item, nil
+	}
+
+	for range stream(context.Background(), streamInv, testItems(100), lookup, StreamBuffer(3)) {
+		time.Sleep(20 * time.Millisecond)
+		break
+	}
+
+	// at most concurrency + buffer items may be in flight or waiting
+	if n := started.Load(); n > 5 {
+		t.Fatalf("started %d lookups before the first result was consumed", n)
+	}
+}
+
+func TestStreamCanceled(t *testing.T) {
+	t.Parallel()
+	streamInv := New("test_key", WithConcurrency(2))
+	ctx, cancel := context.WithCancel(context.Background())
+	lookup := func(ctx context.Context, item string) (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		cancel()
+		return item, nil
+	}
+
+	n, failed := 0, 0
+	for _, result := range stream(ctx, streamInv, testItems(50), lookup) {
+		n++
+		if errors.Is(result.Err, context.Canceled) {
+			failed++
+		}
+	}
+
+	if n != 50 || failed == 0 {
+		t.Fatalf("got %d results, %d canceled; every item should get a result", n, failed)
+	}
+}
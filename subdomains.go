@@ -0,0 +1,82 @@
+package goinvestigate
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// A subdomain seen in DNS traffic.
+type Subdomain struct {
+	Name               string
+	FirstSeen          Timestamp  `json:"firstSeen"`
+	SecurityCategories []string   `json:"securityCategories"`
+	Extra              *RawFields `json:"-"`
+}
+
+func (s *Subdomain) UnmarshalJSON(b []byte) error {
+	type alias Subdomain
+	return decodeFields(b, (*alias)(s), &s.Extra)
+}
+
+// Get a page of up to limit of the subdomains of the given domain, in order
+// of name, starting after the name after. An empty after starts at the
+// first subdomain, and a limit of zero leaves the page size up to the API.
+//
+// For details, see https://sgraph.opendns.com/docs/api#subdomains
+func (inv *Investigate) SubdomainsPage(domain string, after string, limit int) ([]Subdomain, error) {
+	return inv.SubdomainsPageContext(context.Background(), domain, after, limit)
+}
+
+// Like SubdomainsPage, but the request is bound to ctx.
+func (inv *Investigate) SubdomainsPageContext(ctx context.Context, domain string, after string, limit int) ([]Subdomain, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if after != "" {
+		v.Set("offsetName", after)
+	}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+
+	var resp []Subdomain
+	err = inv.GetParseContext(ctx, withQuery(fmt.Sprintf(urls["subdomains"], segment), v), &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Iterate over every subdomain of the given domain, fetching the next page
+// only once the previous one has been consumed, e.g.:
+//
+//	for sub, err := range inv.Subdomains("example.com") {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(sub.Name, sub.FirstSeen.Time)
+//	}
+//
+// Iteration ends after the first error.
+func (inv *Investigate) Subdomains(domain string) iter.Seq2[Subdomain, error] {
+	return inv.SubdomainsContext(context.Background(), domain)
+}
+
+// Like Subdomains, but the requests are bound to ctx.
+func (inv *Investigate) SubdomainsContext(ctx context.Context, domain string) iter.Seq2[Subdomain, error] {
+	// the API may give fewer than were asked for, so only an empty page
+	// means the end
+	return paginateBy(ctx, "", func(ctx context.Context, after string) ([]Subdomain, string, bool, error) {
+		page, err := inv.SubdomainsPageContext(ctx, domain, after, defaultPageSize)
+		if err != nil || len(page) == 0 {
+			return nil, after, false, err
+		}
+		return page, page[len(page)-1].Name, true, nil
+	})
+}
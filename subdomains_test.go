@@ -0,0 +1,101 @@
+package goinvestigate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSubdomainsPage(t *testing.T) {
+	t.Parallel()
+	subInv, _ := newTestClient(t)
+
+	page, err := subInv.SubdomainsPage("example.com", "sub009.example.com", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page) != 5 || page[0].Name != "sub010.example.com" || page[0].FirstSeen.Time.IsZero() {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	if _, err := subInv.SubdomainsPage("not a domain", "", 0); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("%v should be %v", err, ErrInvalidInput)
+	}
+}
+
+func TestSubdomains(t *testing.T) {
+	t.Parallel()
+	subInv, srv := newTestClient(t)
+
+	var names []string
+	for sub, err := range subInv.Subdomains("example.com") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, sub.Name)
+	}
+
+	if len(names) != 250 || names[0] != "sub000.example.com" || names[249] != "sub249.example.com" {
+		t.Fatalf("got %d subdomains, %v ... ", len(names), names[:1])
+	}
+
+	// 2 full pages, a short one, then an empty one
+	if n := len(srv.Requests()); n != 4 {
+		t.Fatalf("made %d requests, should make 4", n)
+	}
+}
+
+func TestSubdomainsStopEarly(t *testing.T) {
+	t.Parallel()
+	subInv, srv := newTestClient(t)
+
+	count := 0
+	for _, err := range subInv.SubdomainsContext(context.Background(), "example.com") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+		if count == 10 {
+			break
+		}
+	}
+
+	// the later pages are only fetched once they're needed
+	if n := len(srv.Requests()); n != 1 {
+		t.Fatalf("made %d requests, should make 1", n)
+	}
+}
+
+func TestSubdomainsSmallPages(t *testing.T) {
+	t.Parallel()
+	// a server which gives at most 10 subdomains a page, whatever the limit
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		after := r.URL.Query().Get("offsetName")
+		var page []map[string]string
+		for i := 0; i < 25 && len(page) < 10; i++ {
+			if name := fmt.Sprintf("sub%02d.example.com", i); name > after {
+				page = append(page, map[string]string{"name": name})
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer ts.Close()
+	subInv := New("test_key", WithBaseURL(ts.URL))
+
+	count := 0
+	for _, err := range subInv.Subdomains("example.com") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+
+	if count != 25 {
+		t.Fatalf("got %d subdomains, should get 25", count)
+	}
+}
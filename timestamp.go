@@ -0,0 +1,108 @@
+package goinvestigate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// the value the API gives for the end of a period which hasn't ended yet
+const currentTime = "Current"
+
+// the layouts timestamps in responses come in, all in UTC
+var timestampLayouts = []string{
+	timeLayout,
+	"2006-01-02",
+	time.RFC3339,
+	// the ISO timestamps of the passive DNS endpoints, to the minute
+	"2006-01-02T15:04Z07:00",
+}
+
+// A Timestamp is a time from a response, along with the value it was parsed
+// from. The end of an open-ended period is "Current", which has a zero Time.
+type Timestamp struct {
+	Time time.Time
+	Raw  string
+}
+
+// Parse a timestamp as given by the API, e.g. "2014/04/07/15", "2014-04-07",
+// "Current", or seconds since the epoch such as "1548797839".
+func ParseTimestamp(raw string) (Timestamp, error) {
+	if raw == "" || raw == currentTime {
+		return Timestamp{Raw: raw}, nil
+	}
+
+	if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return Timestamp{Time: time.Unix(secs, 0).UTC(), Raw: raw}, nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return Timestamp{Time: t, Raw: raw}, nil
+		}
+	}
+	return Timestamp{}, fmt.Errorf("%w: unrecognized timestamp %q", ErrMalformedResponse, raw)
+}
+
+// Whether the timestamp is "Current", i.e. the period it ends hasn't ended.
+func (ts Timestamp) IsCurrent() bool {
+	return ts.Raw == currentTime
+}
+
+// The time, or now if the timestamp is "Current".
+func (ts Timestamp) TimeOr(now time.Time) time.Time {
+	if ts.IsCurrent() {
+		return now
+	}
+	return ts.Time
+}
+
+func (ts Timestamp) String() string {
+	return ts.Raw
+}
+
+func (ts *Timestamp) UnmarshalJSON(b []byte) error {
+	var raw string
+	if len(b) > 0 && b[0] != '"' && string(b) != "null" {
+		// seconds since the epoch, as a number
+		var secs json.Number
+		if err := json.Unmarshal(b, &secs); err != nil {
+			return err
+		}
+		raw = secs.String()
+	} else if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	parsed, err := ParseTimestamp(raw)
+	if err != nil {
+		return err
+	}
+	*ts = parsed
+	return nil
+}
+
+func (ts Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ts.Raw)
+}
+
+// Whether the period is still going on.
+func (p PeriodType) IsCurrent() bool {
+	return p.End.IsCurrent()
+}
+
+// How long the period lasted, up to now if it's still going on.
+func (p PeriodType) Duration() time.Duration {
+	return p.End.TimeOr(time.Now().UTC()).Sub(p.Begin.Time)
+}
+
+// Whether the records are still being seen.
+func (rrp ResourceRecordPeriod) IsCurrent() bool {
+	return rrp.LastSeen.IsCurrent()
+}
+
+// How long the records were seen for, up to now if they still are.
+func (rrp ResourceRecordPeriod) Duration() time.Duration {
+	return rrp.LastSeen.TimeOr(time.Now().UTC()).Sub(rrp.FirstSeen.Time)
+}
@@ -0,0 +1,84 @@
+package goinvestigate
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustTimestamp(t *testing.T, raw string) Timestamp {
+	ts, err := ParseTimestamp(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestParseTimestamp(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		raw  string
+		time time.Time
+	}{
+		{"2014/04/07/15", time.Date(2014, 4, 7, 15, 0, 0, 0, time.UTC)},
+		{"2014-04-07", time.Date(2014, 4, 7, 0, 0, 0, 0, time.UTC)},
+		{"2014-04-07T15:04:05Z", time.Date(2014, 4, 7, 15, 4, 5, 0, time.UTC)},
+		{"Current", time.Time{}},
+		{"1548797839", time.Date(2019, 1, 29, 21, 37, 19, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		ts, err := ParseTimestamp(test.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !ts.Time.Equal(test.time) || ts.Raw != test.raw {
+			t.Fatalf("%q: got %v, should be %v", test.raw, ts.Time, test.time)
+		}
+	}
+
+	if _, err := ParseTimestamp("last tuesday"); !errors.Is(err, ErrMalformedResponse) {
+		t.Fatalf("%v should be %v", err, ErrMalformedResponse)
+	}
+}
+
+func TestTimestampEpochNumber(t *testing.T) {
+	t.Parallel()
+	var ts Timestamp
+	if err := json.Unmarshal([]byte("1548797839"), &ts); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ts.Time.Equal(time.Unix(1548797839, 0)) || ts.Raw != "1548797839" {
+		t.Fatalf("unexpected timestamp %+v", ts)
+	}
+}
+
+func TestPeriodDuration(t *testing.T) {
+	t.Parallel()
+	var p PeriodType
+	if err := json.Unmarshal([]byte(`{"begin": "2014-03-04", "end": "2014-03-05"}`), &p); err != nil {
+		t.Fatal(err)
+	}
+
+	if p.IsCurrent() || p.Duration() != 24*time.Hour {
+		t.Fatalf("%v should be a day long", p)
+	}
+
+	var rrp ResourceRecordPeriod
+	if err := json.Unmarshal([]byte(`{"first_seen": "2014/03/04/00", "last_seen": "Current"}`), &rrp); err != nil {
+		t.Fatal(err)
+	}
+
+	if !rrp.IsCurrent() || rrp.Duration() < 24*time.Hour {
+		t.Fatalf("%v should still be going on", rrp)
+	}
+
+	// the raw value is kept when encoding
+	b, err := json.Marshal(rrp.LastSeen)
+	if err != nil || string(b) != `"Current"` {
+		t.Fatalf("got %s, %v", b, err)
+	}
+}
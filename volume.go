@@ -0,0 +1,180 @@
+package goinvestigate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Which queries count towards a domain's volume.
+type VolumeMatch string
+
+const (
+	// Queries for the domain and all of its subdomains
+	MatchAll VolumeMatch = "all"
+	// Queries for exactly the domain
+	MatchExact VolumeMatch = "exact"
+	// Queries for subdomains of the domain, but not the domain itself
+	MatchComponent VolumeMatch = "component"
+)
+
+// the resolution of the volume endpoint's counts
+const volumeInterval = time.Hour
+
+// The number of queries for a domain in the interval starting at Time.
+type VolumePoint struct {
+	Time  time.Time
+	Count int
+}
+
+// A series of query counts, in order of time.
+type VolumeSeries []VolumePoint
+
+// The query volume of a domain, counted every hour.
+type DomainVolume struct {
+	// When the first and last counts start
+	Start  time.Time
+	Stop   time.Time
+	Points VolumeSeries
+	Extra  *RawFields `json:"-"`
+}
+
+func (dv *DomainVolume) UnmarshalJSON(b []byte) error {
+	// the API gives the times of the first and last counts in milliseconds
+	// since the epoch, and every count in between
+	var resp struct {
+		Dates   []int64
+		Queries []int
+	}
+	if err := decodeFields(b, &resp, &dv.Extra); err != nil {
+		return err
+	}
+
+	if len(resp.Dates) != 2 {
+		return fmt.Errorf("%w: volume has %d dates, should have 2", ErrMalformedResponse, len(resp.Dates))
+	}
+
+	dv.Start = time.UnixMilli(resp.Dates[0]).UTC()
+	dv.Stop = time.UnixMilli(resp.Dates[1]).UTC()
+	dv.Points = make(VolumeSeries, len(resp.Queries))
+	for i, count := range resp.Queries {
+		dv.Points[i] = VolumePoint{
+			Time:  dv.Start.Add(time.Duration(i) * volumeInterval),
+			Count: count,
+		}
+	}
+	return nil
+}
+
+// The total count of the series.
+func (s VolumeSeries) Total() int {
+	total := 0
+	for _, p := range s {
+		total += p.Count
+	}
+	return total
+}
+
+// Resample the series into intervals of the given length, e.g. time.Hour*24
+// for daily counts, summing the counts which fall into each. Intervals are
+// aligned as by time.Time.Truncate, and those with no points are left out.
+// A non-positive interval returns a copy of the series.
+func (s VolumeSeries) Resample(interval time.Duration) VolumeSeries {
+	if interval <= 0 {
+		return append(VolumeSeries(nil), s...)
+	}
+
+	var resampled VolumeSeries
+	for _, p := range s {
+		bucket := p.Time.Truncate(interval)
+		if n := len(resampled); n > 0 && resampled[n-1].Time.Equal(bucket) {
+			resampled[n-1].Count += p.Count
+			continue
+		}
+		resampled = append(resampled, VolumePoint{Time: bucket, Count: p.Count})
+	}
+	return resampled
+}
+
+// How the count changed from one point of a series to the next.
+type VolumeChange struct {
+	// The time of the later point
+	Time time.Time
+	// The difference between the counts
+	Delta int
+	// The difference relative to the earlier count, e.g. 1 when the count
+	// doubles. It's +Inf when the count rises from zero, and 0 when it stays
+	// there.
+	Ratio float64
+}
+
+// The rate of change between each point of the series and the one before
+// it, for spotting sudden spikes in volume. The result has one fewer entry
+// than the series.
+func (s VolumeSeries) RateOfChange() []VolumeChange {
+	if len(s) < 2 {
+		return nil
+	}
+
+	changes := make([]VolumeChange, len(s)-1)
+	for i := 1; i < len(s); i++ {
+		prev, cur := s[i-1].Count, s[i].Count
+		change := VolumeChange{Time: s[i].Time, Delta: cur - prev}
+		switch {
+		case prev != 0:
+			change.Ratio = float64(change.Delta) / float64(prev)
+		case cur > 0:
+			change.Ratio = math.Inf(1)
+		}
+		changes[i-1] = change
+	}
+	return changes
+}
+
+// Get the number of queries for the given domain every hour between start
+// and stop. A zero start or stop leaves it up to the API, which defaults to
+// the last week, and an empty match counts all queries.
+//
+// For details, see https://sgraph.opendns.com/docs/api#domain-volume
+func (inv *Investigate) DomainVolume(domain string, start, stop time.Time, match VolumeMatch) (*DomainVolume, error) {
+	return inv.DomainVolumeContext(context.Background(), domain, start, stop, match)
+}
+
+// Like DomainVolume, but the request is bound to ctx.
+func (inv *Investigate) DomainVolumeContext(ctx context.Context, domain string, start, stop time.Time, match VolumeMatch) (*DomainVolume, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	switch match {
+	case "", MatchAll, MatchExact, MatchComponent:
+	default:
+		return nil, &ValidationError{Kind: "match", Input: string(match), Reason: "not all, exact or component"}
+	}
+
+	if !start.IsZero() && !stop.IsZero() && !start.Before(stop) {
+		return nil, &ValidationError{Kind: "time range", Input: start.String() + " to " + stop.String(), Reason: "start is not before stop"}
+	}
+
+	v := url.Values{}
+	if !start.IsZero() {
+		v.Set("start", strconv.FormatInt(start.UnixMilli(), 10))
+	}
+	if !stop.IsZero() {
+		v.Set("stop", strconv.FormatInt(stop.UnixMilli(), 10))
+	}
+	if match != "" {
+		v.Set("match", string(match))
+	}
+
+	resp := new(DomainVolume)
+	err = inv.GetParseContext(ctx, withQuery(fmt.Sprintf(urls["volume"], segment), v), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
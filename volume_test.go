@@ -0,0 +1,95 @@
+package goinvestigate
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDomainVolume(t *testing.T) {
+	t.Parallel()
+	volInv, _ := newTestClient(t)
+
+	start := time.Date(2017, 12, 14, 19, 0, 0, 0, time.UTC)
+	stop := time.Date(2017, 12, 16, 18, 0, 0, 0, time.UTC)
+	out, err := volInv.DomainVolume("www.example.com", start, stop, MatchExact)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !out.Start.Equal(start) || !out.Stop.Equal(stop) || len(out.Points) != 48 {
+		t.Fatalf("got %v to %v with %d points", out.Start, out.Stop, len(out.Points))
+	}
+
+	last := out.Points[len(out.Points)-1]
+	if !last.Time.Equal(stop) || last.Count != 280 {
+		t.Fatalf("unexpected last point %+v", last)
+	}
+
+	if _, err := volInv.DomainVolume("www.example.com", stop, start, MatchAll); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("%v should be %v", err, ErrInvalidInput)
+	}
+
+	if _, err := volInv.DomainVolume("www.example.com", start, stop, "some"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("%v should be %v", err, ErrInvalidInput)
+	}
+}
+
+func TestDomainVolumeMalformed(t *testing.T) {
+	t.Parallel()
+	var dv DomainVolume
+	if err := json.Unmarshal([]byte(`{"dates": [], "queries": [1]}`), &dv); !errors.Is(err, ErrMalformedResponse) {
+		t.Fatalf("%v should be %v", err, ErrMalformedResponse)
+	}
+}
+
+func hourlySeries(start time.Time, counts ...int) VolumeSeries {
+	series := make(VolumeSeries, len(counts))
+	for i, count := range counts {
+		series[i] = VolumePoint{Time: start.Add(time.Duration(i) * time.Hour), Count: count}
+	}
+	return series
+}
+
+func TestVolumeResample(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2017, 12, 14, 22, 0, 0, 0, time.UTC)
+	series := hourlySeries(start, 1, 2, 3, 4, 5)
+
+	daily := series.Resample(24 * time.Hour)
+	if len(daily) != 2 || daily[0].Count != 3 || daily[1].Count != 12 {
+		t.Fatalf("unexpected resampled series %+v", daily)
+	}
+
+	if !daily[1].Time.Equal(time.Date(2017, 12, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected interval start %v", daily[1].Time)
+	}
+
+	if daily.Total() != series.Total() {
+		t.Fatalf("resampling changed the total from %d to %d", series.Total(), daily.Total())
+	}
+}
+
+func TestVolumeRateOfChange(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2017, 12, 14, 0, 0, 0, 0, time.UTC)
+	changes := hourlySeries(start, 0, 0, 10, 40, 20).RateOfChange()
+
+	ratios := []float64{0, math.Inf(1), 3, -0.5}
+	deltas := []int{0, 10, 30, -20}
+	if len(changes) != len(ratios) {
+		t.Fatalf("got %d changes, should get %d", len(changes), len(ratios))
+	}
+
+	for i, change := range changes {
+		if change.Ratio != ratios[i] || change.Delta != deltas[i] || !change.Time.Equal(start.Add(time.Duration(i+1)*time.Hour)) {
+			t.Fatalf("%d: unexpected change %+v", i, change)
+		}
+	}
+
+	if changes := hourlySeries(start, 1).RateOfChange(); changes != nil {
+		t.Fatalf("a single point should have no changes, got %v", changes)
+	}
+}
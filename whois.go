@@ -0,0 +1,283 @@
+package goinvestigate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// The details of one of the contacts in a WhoisRecord.
+type WhoisContact struct {
+	Name         string
+	Organization string
+	Email        string
+	Street       []string
+	City         string
+	State        string
+	PostalCode   string
+	Country      string
+	Telephone    string
+	TelephoneExt string
+	Fax          string
+	FaxExt       string
+}
+
+// A WHOIS record of a domain. The API gives the contacts as flat fields
+// with a prefix for each contact, e.g. "registrantEmail", which are
+// gathered into a WhoisContact for each.
+type WhoisRecord struct {
+	DomainName                string `json:"domainName"`
+	RegistrarName             string `json:"registrarName"`
+	RegistrarIANAID           string `json:"registrarIANAID"`
+	Created                   Timestamp
+	Updated                   Timestamp
+	Expires                   Timestamp
+	Status                    []string
+	NameServers               []string `json:"nameServers"`
+	WhoisServers              string   `json:"whoisServers"`
+	Emails                    []string
+	Addresses                 []string
+	RecordExpired             bool   `json:"recordExpired"`
+	HasRawText                bool   `json:"hasRawText"`
+	AuditUpdatedDate          string `json:"auditUpdatedDate"`
+	Timestamp                 int64
+	TimeOfLatestRealtimeCheck int64 `json:"timeOfLatestRealtimeCheck"`
+
+	Registrant            WhoisContact `json:"-"`
+	AdministrativeContact WhoisContact `json:"-"`
+	TechnicalContact      WhoisContact `json:"-"`
+	BillingContact        WhoisContact `json:"-"`
+	ZoneContact           WhoisContact `json:"-"`
+
+	Extra *RawFields `json:"-"`
+}
+
+func (w *WhoisRecord) UnmarshalJSON(b []byte) error {
+	type alias WhoisRecord
+	if err := decodeFields(b, (*alias)(w), &w.Extra); err != nil {
+		return err
+	}
+	if w.Extra == nil {
+		return nil
+	}
+
+	// the contact fields are unknown to the alias, so take them back out
+	contacts := map[string]*WhoisContact{
+		"registrant":            &w.Registrant,
+		"administrativeContact": &w.AdministrativeContact,
+		"technicalContact":      &w.TechnicalContact,
+		"billingContact":        &w.BillingContact,
+		"zoneContact":           &w.ZoneContact,
+	}
+	known := knownFields(reflect.TypeOf(WhoisContact{}))
+	for prefix, contact := range contacts {
+		fields := make(map[string]json.RawMessage)
+		for name, value := range w.Extra.Unknown {
+			if field, ok := strings.CutPrefix(name, prefix); ok && known[strings.ToLower(field)] {
+				fields[field] = value
+				delete(w.Extra.Unknown, name)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		contactJSON, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(contactJSON, contact); err != nil {
+			return err
+		}
+	}
+
+	if len(w.Extra.Unknown) == 0 {
+		w.Extra = nil
+	}
+	return nil
+}
+
+// A domain registered with an email address or name server.
+type WhoisDomain struct {
+	Domain string
+	// Whether the domain's current WHOIS record has the email address or
+	// name server, rather than only a past one
+	Current bool
+	Extra   *RawFields `json:"-"`
+}
+
+func (wd *WhoisDomain) UnmarshalJSON(b []byte) error {
+	type alias WhoisDomain
+	return decodeFields(b, (*alias)(wd), &wd.Extra)
+}
+
+// A page of the domains registered with an email address or name server.
+type WhoisDomains struct {
+	TotalResults      int  `json:"totalResults"`
+	MoreDataAvailable bool `json:"moreDataAvailable"`
+	Limit             int
+	Domains           []WhoisDomain
+	Extra             *RawFields `json:"-"`
+}
+
+func (wd *WhoisDomains) UnmarshalJSON(b []byte) error {
+	type alias WhoisDomains
+	return decodeFields(b, (*alias)(wd), &wd.Extra)
+}
+
+// Normalize an email address the way WhoisByEmail does before using it in
+// a request: surrounding space is removed and it is lowercased. It is only
+// checked loosely, since WHOIS records hold all sorts.
+func NormalizeEmail(email string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(email))
+	local, domain, ok := strings.Cut(normalized, "@")
+	if !ok || local == "" || domain == "" || strings.ContainsAny(normalized, "/?# ") {
+		return "", &ValidationError{Kind: "email", Input: email, Reason: "not an email address"}
+	}
+	return normalized, nil
+}
+
+// Normalizes email, and escapes it for use as a path segment.
+func emailSegment(email string) (string, error) {
+	normalized, err := NormalizeEmail(email)
+	if err != nil {
+		return "", err
+	}
+	return url.PathEscape(normalized), nil
+}
+
+// Get the current WHOIS record of the given domain.
+//
+// For details, see https://sgraph.opendns.com/docs/api#whois
+func (inv *Investigate) Whois(domain string) (*WhoisRecord, error) {
+	return inv.WhoisContext(context.Background(), domain)
+}
+
+// Like Whois, but the request is bound to ctx.
+func (inv *Investigate) WhoisContext(ctx context.Context, domain string) (*WhoisRecord, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
+	resp := new(WhoisRecord)
+	err = inv.GetParseContext(ctx, fmt.Sprintf(urls["whois"], segment), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Get a page of the historical WHOIS records of the given domain, newest
+// first.
+//
+// For details, see https://sgraph.opendns.com/docs/api#whois
+func (inv *Investigate) WhoisHistory(domain string, page Page) ([]WhoisRecord, error) {
+	return inv.WhoisHistoryContext(context.Background(), domain, page)
+}
+
+// Like WhoisHistory, but the request is bound to ctx.
+func (inv *Investigate) WhoisHistoryContext(ctx context.Context, domain string, page Page) ([]WhoisRecord, error) {
+	segment, err := domainSegment(domain)
+	if err != nil {
+		return nil, err
+	}
+	v := url.Values{}
+	page.encode(v)
+	var resp []WhoisRecord
+	err = inv.GetParseContext(ctx, withQuery(fmt.Sprintf(urls["whois_history"], segment), v), &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Iterate over every historical WHOIS record of the given domain, fetching
+// more pages as they are needed.
+func (inv *Investigate) WhoisHistoryAll(ctx context.Context, domain string) iter.Seq2[WhoisRecord, error] {
+	return paginate(ctx, defaultPageSize, func(ctx context.Context, page Page) ([]WhoisRecord, bool, error) {
+		records, err := inv.WhoisHistoryContext(ctx, domain, page)
+		return records, len(records) == page.Limit, err
+	})
+}
+
+// Get a page of the domains whose WHOIS records have the given email
+// address.
+//
+// For details, see https://sgraph.opendns.com/docs/api#whois
+func (inv *Investigate) WhoisByEmail(email string, page Page) (*WhoisDomains, error) {
+	return inv.WhoisByEmailContext(context.Background(), email, page)
+}
+
+// Like WhoisByEmail, but the request is bound to ctx.
+func (inv *Investigate) WhoisByEmailContext(ctx context.Context, email string, page Page) (*WhoisDomains, error) {
+	segment, err := emailSegment(email)
+	if err != nil {
+		return nil, err
+	}
+	return inv.whoisDomains(ctx, fmt.Sprintf(urls["whois_emails"], segment), page)
+}
+
+// Iterate over every domain whose WHOIS records have the given email
+// address, fetching more pages as they are needed.
+func (inv *Investigate) WhoisByEmailAll(ctx context.Context, email string) iter.Seq2[WhoisDomain, error] {
+	return paginate(ctx, defaultPageSize, func(ctx context.Context, page Page) ([]WhoisDomain, bool, error) {
+		resp, err := inv.WhoisByEmailContext(ctx, email, page)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Domains, resp.MoreDataAvailable, nil
+	})
+}
+
+// Get a page of the domains whose WHOIS records have the given name
+// server.
+//
+// For details, see https://sgraph.opendns.com/docs/api#whois
+func (inv *Investigate) WhoisByNameserver(nameserver string, page Page) (*WhoisDomains, error) {
+	return inv.WhoisByNameserverContext(context.Background(), nameserver, page)
+}
+
+// Like WhoisByNameserver, but the request is bound to ctx.
+func (inv *Investigate) WhoisByNameserverContext(ctx context.Context, nameserver string, page Page) (*WhoisDomains, error) {
+	segment, err := domainSegment(nameserver)
+	if err != nil {
+		return nil, err
+	}
+	return inv.whoisDomains(ctx, fmt.Sprintf(urls["whois_nameservers"], segment), page)
+}
+
+// Iterate over every domain whose WHOIS records have the given name server,
+// fetching more pages as they are needed.
+func (inv *Investigate) WhoisByNameserverAll(ctx context.Context, nameserver string) iter.Seq2[WhoisDomain, error] {
+	return paginate(ctx, defaultPageSize, func(ctx context.Context, page Page) ([]WhoisDomain, bool, error) {
+		resp, err := inv.WhoisByNameserverContext(ctx, nameserver, page)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Domains, resp.MoreDataAvailable, nil
+	})
+}
+
+// Fetches a page of the email or name server endpoint at path. Their
+// responses are keyed by the email address or name server.
+func (inv *Investigate) whoisDomains(ctx context.Context, path string, page Page) (*WhoisDomains, error) {
+	v := url.Values{}
+	page.encode(v)
+	resp := make(map[string]WhoisDomains)
+	err := inv.GetParseContext(ctx, withQuery(path, v), &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp) != 1 {
+		return nil, ErrMalformedResponse
+	}
+	for _, domains := range resp {
+		return &domains, nil
+	}
+	return nil, ErrMalformedResponse
+}
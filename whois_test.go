@@ -0,0 +1,82 @@
+package goinvestigate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWhois(t *testing.T) {
+	t.Parallel()
+	whoisInv, _ := newTestClient(t, WithDecodeMode(DecodeStrict))
+
+	out, err := whoisInv.Whois("Example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.DomainName != "example.com" || out.RegistrarIANAID != "292" || len(out.NameServers) != 2 {
+		t.Fatalf("unexpected record %+v", out)
+	}
+
+	if !out.Created.Time.Equal(time.Date(1997, 9, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected creation date %v", out.Created)
+	}
+
+	if out.Registrant.Organization != "Example Inc." || out.Registrant.Street[0] != "1600 Amphitheatre Parkway" ||
+		out.TechnicalContact.Email != "dns-admin@example.com" {
+		t.Fatalf("contacts weren't decoded: %+v, %+v", out.Registrant, out.TechnicalContact)
+	}
+
+	if out.Extra != nil {
+		t.Fatalf("unexpected unknown fields %v", out.Extra.Unknown)
+	}
+
+	if _, err := whoisInv.WhoisByEmail("not an email", Page{}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("%v should be %v", err, ErrInvalidInput)
+	}
+}
+
+func TestWhoisPaging(t *testing.T) {
+	t.Parallel()
+	whoisInv, _ := newTestClient(t)
+
+	page, err := whoisInv.WhoisByEmail("dns-admin@example.com", Page{Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Domains) != 2 || page.Domains[0].Domain != "example2.com" || !page.MoreDataAvailable || page.TotalResults != 5 {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	var domains []string
+	for domain, err := range whoisInv.WhoisByNameserverAll(context.Background(), "ns1.example.com") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		domains = append(domains, domain.Domain)
+	}
+
+	if len(domains) != 5 || domains[4] != "example4.com" {
+		t.Fatalf("unexpected domains %v", domains)
+	}
+
+	records := 0
+	for _, err := range whoisInv.WhoisHistoryAll(context.Background(), "example.com") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		records++
+	}
+
+	if records != 5 {
+		t.Fatalf("got %d records", records)
+	}
+
+	history, err := whoisInv.WhoisHistory("example.com", Page{Limit: 2, Offset: 4})
+	if err != nil || len(history) != 1 {
+		t.Fatalf("got %d records, %v", len(history), err)
+	}
+}